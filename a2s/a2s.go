@@ -0,0 +1,364 @@
+// Package a2s implements the Steam server query protocol (A2S_INFO and A2S_PLAYER) spoken by Source engine
+// games and many other RCon-compatible servers, returning server details and online players without credentials
+package a2s
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/jake-young-dev/mcr/internal/ctxerr"
+)
+
+const (
+	//default values
+	DefaultPort    = 27015
+	DefaultTimeout = time.Second * 5
+
+	//request and reply headers
+	headerInfo      = 0x54
+	headerPlayer    = 0x55
+	headerInfoReply = 0x49
+	headerPlayers   = 0x44
+	headerChallenge = 0x41
+
+	//packet prefixes
+	singlePacket = -1
+	splitPacket  = -2
+
+	//servers answering with a new challenge this many times are treated as broken
+	maxChallenges = 3
+)
+
+var (
+	//returned when the server reply cannot be parsed
+	ErrMalformedResponse = errors.New("malformed a2s response")
+	//returned for split responses compressed with bzip2, only sent by some older engines
+	ErrCompressed = errors.New("compressed a2s responses are not supported")
+)
+
+// server details returned by A2S_INFO, fields after Version are only set when the server sends them
+type Info struct {
+	Protocol    byte
+	Name        string
+	Map         string
+	Folder      string
+	Game        string
+	AppID       uint16
+	Players     int
+	MaxPlayers  int
+	Bots        int
+	ServerType  byte //d for dedicated, l for listen, p for SourceTV
+	Environment byte //l for linux, w for windows, m or o for mac
+	Visibility  byte //1 when the server is password protected
+	VAC         byte
+	Version     string
+	Port        uint16
+	SteamID     uint64
+	TVPort      uint16
+	TVName      string
+	Keywords    string
+	GameID      uint64
+}
+
+// player entry returned by A2S_PLAYER
+type Player struct {
+	Index    byte
+	Name     string
+	Score    int32
+	Duration time.Duration //time connected to the server
+}
+
+// a2s client, each request uses a new socket so the client is safe for concurrent use
+type Client struct {
+	address string
+	port    int
+	timeout time.Duration
+}
+
+// client option func skeleton
+type Option func(c *Client)
+
+// option to allow for custom port values
+func WithPort(port int) Option {
+	return func(c *Client) {
+		c.port = port
+	}
+}
+
+// option to allow for custom timeouts, the timeout bounds each request including challenges
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// creates a new a2s client configured with the supplied options
+func NewClient(addr string, opts ...Option) *Client {
+	c := &Client{
+		address: addr,
+		port:    DefaultPort,
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// requests the server details
+func (c *Client) Info(ctx context.Context) (*Info, error) {
+	res, err := c.request(ctx, headerInfoReply, func(challenge []byte) []byte {
+		req := append([]byte{0xff, 0xff, 0xff, 0xff, headerInfo}, "Source Engine Query\x00"...)
+		return append(req, challenge...) //challenge is only sent once the server asks for it
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{buf: res}
+	info := &Info{
+		Protocol: r.byte(),
+		Name:     r.string(),
+		Map:      r.string(),
+		Folder:   r.string(),
+		Game:     r.string(),
+		AppID:    r.uint16(),
+	}
+	info.Players = int(r.byte())
+	info.MaxPlayers = int(r.byte())
+	info.Bots = int(r.byte())
+	info.ServerType = r.byte()
+	info.Environment = r.byte()
+	info.Visibility = r.byte()
+	info.VAC = r.byte()
+	info.Version = r.string()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	//extra data flag
+	if len(r.buf) == 0 {
+		return info, nil
+	}
+	edf := r.byte()
+	if edf&0x80 != 0 {
+		info.Port = r.uint16()
+	}
+	if edf&0x10 != 0 {
+		info.SteamID = r.uint64()
+	}
+	if edf&0x40 != 0 {
+		info.TVPort = r.uint16()
+		info.TVName = r.string()
+	}
+	if edf&0x20 != 0 {
+		info.Keywords = r.string()
+	}
+	if edf&0x01 != 0 {
+		info.GameID = r.uint64()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return info, nil
+}
+
+// requests the online players
+func (c *Client) Players(ctx context.Context) ([]Player, error) {
+	res, err := c.request(ctx, headerPlayers, func(challenge []byte) []byte {
+		if challenge == nil {
+			challenge = []byte{0xff, 0xff, 0xff, 0xff} //asks the server for a challenge
+		}
+		return append([]byte{0xff, 0xff, 0xff, 0xff, headerPlayer}, challenge...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{buf: res}
+	count := int(r.byte())
+	players := make([]Player, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		p := Player{
+			Index: r.byte(),
+			Name:  r.string(),
+			Score: int32(r.uint32()),
+		}
+		p.Duration = time.Duration(float64(math.Float32frombits(r.uint32())) * float64(time.Second))
+		players = append(players, p)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return players, nil
+}
+
+// sends the request built for the current challenge until the server replies with the expected header,
+// returning the reply body after the header
+func (c *Client) request(ctx context.Context, expect byte, build func(challenge []byte) []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", net.JoinHostPort(c.address, strconv.Itoa(c.port)))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	var challenge []byte
+	for i := 0; i <= maxChallenges; i++ {
+		_, err = conn.Write(build(challenge))
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err)
+		}
+		res, err := readResponse(conn)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err)
+		}
+		if len(res) < 1 {
+			return nil, fmt.Errorf("%w: empty reply", ErrMalformedResponse)
+		}
+
+		switch res[0] {
+		case expect:
+			return res[1:], nil
+		case headerChallenge:
+			if len(res) < 5 {
+				return nil, fmt.Errorf("%w: short challenge", ErrMalformedResponse)
+			}
+			challenge = append([]byte{}, res[1:5]...)
+		default:
+			return nil, fmt.Errorf("%w: unexpected header %#x", ErrMalformedResponse, res[0])
+		}
+	}
+	return nil, fmt.Errorf("%w: server kept sending challenges", ErrMalformedResponse)
+}
+
+// reads a response, joining split packets, and returns the payload after the single packet prefix
+func readResponse(conn net.Conn) ([]byte, error) {
+	buf := make([]byte, 1400*4) //servers split packets at 1248 or 1400 bytes
+	var parts [][]byte
+	var id uint32
+	received := 0
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n < 4 {
+			return nil, fmt.Errorf("%w: %d byte packet", ErrMalformedResponse, n)
+		}
+		switch int32(binary.LittleEndian.Uint32(buf)) {
+		case singlePacket:
+			return append([]byte{}, buf[4:n]...), nil
+		case splitPacket:
+		default:
+			return nil, fmt.Errorf("%w: unknown packet prefix", ErrMalformedResponse)
+		}
+
+		//split header: id, total, number, size
+		if n < 12 {
+			return nil, fmt.Errorf("%w: short split header", ErrMalformedResponse)
+		}
+		pid := binary.LittleEndian.Uint32(buf[4:8])
+		if pid&0x80000000 != 0 {
+			return nil, ErrCompressed
+		}
+		total, number := int(buf[8]), int(buf[9])
+		if parts == nil {
+			if total == 0 {
+				return nil, fmt.Errorf("%w: empty split response", ErrMalformedResponse)
+			}
+			parts = make([][]byte, total)
+			id = pid
+		}
+		if pid != id || total != len(parts) || number >= total {
+			continue //part of another response or corrupt
+		}
+		if parts[number] == nil { //duplicated parts are ignored
+			parts[number] = append([]byte{}, buf[12:n]...)
+			received++
+		}
+		if received < total {
+			continue
+		}
+
+		res := bytes.Join(parts, nil)
+		if len(res) < 4 || int32(binary.LittleEndian.Uint32(res)) != singlePacket {
+			return nil, fmt.Errorf("%w: split payload missing prefix", ErrMalformedResponse)
+		}
+		return res[4:], nil
+	}
+}
+
+// sequential little endian parser, the first error is kept and later reads return zero values
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.err = fmt.Errorf("%w: unexpected end of reply", ErrMalformedResponse)
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *reader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.buf, 0)
+	if i < 0 {
+		r.err = fmt.Errorf("%w: unterminated string", ErrMalformedResponse)
+		return ""
+	}
+	s := string(r.buf[:i])
+	r.buf = r.buf[i+1:]
+	return s
+}
@@ -0,0 +1,121 @@
+package a2s
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+var testChallenge = []byte{0x0a, 0x0b, 0x0c, 0x0d}
+
+// starts a udp server that challenges every request and splits the player list across two packets
+func startTestServer(t *testing.T) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	prefix := []byte{0xff, 0xff, 0xff, 0xff}
+	go func() {
+		buf := make([]byte, 1400)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req := buf[:n]
+			if n < 9 || !bytes.HasSuffix(req, testChallenge) {
+				conn.WriteTo(append(append(prefix, headerChallenge), testChallenge...), addr)
+				continue
+			}
+
+			switch req[4] {
+			case headerInfo:
+				res := append(prefix, headerInfoReply, 17)
+				res = append(res, "Test Server\x00de_dust2\x00csgo\x00Counter-Strike\x00"...)
+				res = binary.LittleEndian.AppendUint16(res, 730)
+				res = append(res, 2, 16, 0, 'd', 'l', 0, 1)
+				res = append(res, "1.38.7.9\x00"...)
+				res = append(res, 0x80|0x20)
+				res = binary.LittleEndian.AppendUint16(res, 27015)
+				res = append(res, "secure,casual\x00"...)
+				conn.WriteTo(res, addr)
+			case headerPlayer:
+				body := append(prefix, headerPlayers, 2)
+				for i, name := range []string{"gabe", "robin"} {
+					body = append(body, byte(i))
+					body = append(body, name+"\x00"...)
+					body = binary.LittleEndian.AppendUint32(body, uint32(10*(i+1)))
+					body = binary.LittleEndian.AppendUint32(body, math.Float32bits(90.5))
+				}
+				//second part first to check parts are ordered by number
+				half := len(body) / 2
+				for _, part := range []int{1, 0, 1} {
+					res := []byte{0xfe, 0xff, 0xff, 0xff}
+					res = binary.LittleEndian.AppendUint32(res, 7)
+					res = append(res, 2, byte(part))
+					res = binary.LittleEndian.AppendUint16(res, 1248)
+					if part == 0 {
+						res = append(res, body[:half]...)
+					} else {
+						res = append(res, body[half:]...)
+					}
+					conn.WriteTo(res, addr)
+				}
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// testing info requests including the challenge and extra data
+func TestInfo(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t)))
+	info, err := c.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "Test Server" || info.Map != "de_dust2" || info.AppID != 730 || info.Players != 2 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if info.Version != "1.38.7.9" || info.Port != 27015 || info.Keywords != "secure,casual" || info.SteamID != 0 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+}
+
+// testing player requests split across packets
+func TestPlayers(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t)))
+	players, err := c.Players(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 2 || players[1].Name != "robin" || players[1].Score != 20 {
+		t.Fatalf("unexpected players %+v", players)
+	}
+	if players[0].Duration != time.Millisecond*90500 {
+		t.Fatalf("unexpected duration %v", players[0].Duration)
+	}
+}
+
+// testing unanswered requests give up once the timeout passes
+func TestTimeout(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	c := NewClient("127.0.0.1", WithPort(conn.LocalAddr().(*net.UDPAddr).Port), WithTimeout(time.Millisecond*100))
+	_, err = c.Info(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
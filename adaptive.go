@@ -0,0 +1,64 @@
+package mcr
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// number of recent latencies kept for each command verb
+const adaptiveSamples = 32
+
+// learns per-verb response latencies and derives read deadlines from them so slow verbs (locate, forceload)
+// get longer deadlines without raising the deadline for every command
+type adaptiveTimeouts struct {
+	mu    sync.Mutex
+	min   time.Duration
+	max   time.Duration
+	verbs map[string][]time.Duration
+}
+
+// returns the first word of the command used to group latencies
+func commandVerb(cmd string) string {
+	fields := strings.Fields(strings.TrimLeft(cmd, "/ \t"))
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+// returns twice the slowest recent latency for the verb bounded by the configured min and max, verbs without
+// history use the min
+func (a *adaptiveTimeouts) timeout(verb string) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var slowest time.Duration
+	for _, l := range a.verbs[verb] {
+		if l > slowest {
+			slowest = l
+		}
+	}
+
+	timeout := slowest * 2
+	if timeout < a.min {
+		return a.min
+	}
+	if timeout > a.max {
+		return a.max
+	}
+	return timeout
+}
+
+// records the latency of a verb, timed out commands should record the deadline they exceeded so the next
+// deadline is extended
+func (a *adaptiveTimeouts) record(verb string, latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	samples := append(a.verbs[verb], latency)
+	if len(samples) > adaptiveSamples {
+		samples = samples[len(samples)-adaptiveSamples:]
+	}
+	a.verbs[verb] = samples
+}
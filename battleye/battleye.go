@@ -0,0 +1,357 @@
+// Package battleye implements the BattlEye RCon protocol (version 2) used by Arma and DayZ servers. Packets are
+// sent over udp with a crc32 checksum, the client retransmits unanswered packets, acknowledges server messages,
+// and sends keepalives so the server does not drop an idle connection
+package battleye
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+const (
+	//default values
+	DefaultPort       = 2306
+	DefaultTimeout    = time.Second * 10
+	DefaultKeepAlive  = time.Second * 30 //servers drop clients idle for 45 seconds
+	DefaultRetransmit = time.Second
+
+	//packet types
+	typeLogin   = 0x00
+	typeCommand = 0x01
+	typeMessage = 0x02
+)
+
+// battleye client, commands are sent one at a time
+type Client struct {
+	address    string
+	port       int
+	timeout    time.Duration
+	keepAlive  time.Duration
+	retransmit time.Duration
+	onMessage  func(string)
+
+	cmdMu sync.Mutex //serializes commands
+
+	mu          sync.Mutex
+	connection  net.Conn
+	closed      chan struct{} //closed when the reader stops
+	login       chan bool
+	pending     map[byte]*pendingCommand
+	sequence    byte
+	lastMessage int //sequence of the last server message, servers resend messages until acknowledged
+	lastSent    time.Time
+}
+
+// response being collected for a command
+type pendingCommand struct {
+	parts    [][]byte
+	received int
+	done     chan string
+}
+
+// client option func skeleton
+type Option func(c *Client)
+
+// option to allow for custom port values
+func WithPort(port int) Option {
+	return func(c *Client) {
+		c.port = port
+	}
+}
+
+// option to allow for custom timeout values
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// option to send an empty command after the connection is idle for the period, values below one disable
+// keepalives
+func WithKeepAlive(period time.Duration) Option {
+	return func(c *Client) {
+		c.keepAlive = period
+	}
+}
+
+// option to resend unanswered login and command packets every interval until the timeout passes, values
+// below one disable retransmission
+func WithRetransmit(interval time.Duration) Option {
+	return func(c *Client) {
+		c.retransmit = interval
+	}
+}
+
+// option to receive messages pushed by the server such as chat and player connections, each message is
+// delivered once even if the server resends it. The handler runs on the goroutine reading packets and must
+// not block
+func WithMessageHandler(handler func(string)) Option {
+	return func(c *Client) {
+		c.onMessage = handler
+	}
+}
+
+// creates a new battleye client configured with the supplied options
+func NewClient(addr string, opts ...Option) *Client {
+	c := &Client{
+		address:    addr,
+		port:       DefaultPort,
+		timeout:    DefaultTimeout,
+		keepAlive:  DefaultKeepAlive,
+		retransmit: DefaultRetransmit,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// connects and logs in to the server
+func (c *Client) Connect(password string) error {
+	return c.ConnectContext(context.Background(), password)
+}
+
+// connects and logs in to the server, the context bounds the login along with the client timeout
+func (c *Client) ConnectContext(ctx context.Context, password string) error {
+	if password == "" {
+		return mcr.ErrMissingPassword
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	c.mu.Lock()
+	if c.connection != nil {
+		c.mu.Unlock()
+		return mcr.ErrAlreadyConnected
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", net.JoinHostPort(c.address, strconv.Itoa(c.port)))
+	if err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	c.connection = conn
+	c.closed = make(chan struct{})
+	c.login = make(chan bool, 1)
+	c.pending = make(map[byte]*pendingCommand)
+	c.sequence = 0
+	c.lastMessage = -1
+	c.lastSent = time.Now()
+	closed, login := c.closed, c.login
+	c.mu.Unlock()
+	go c.read(conn, closed)
+
+	ok, err := await(ctx, conn, closed, encode(typeLogin, []byte(password)), login, c.retransmit)
+	if err == nil && !ok {
+		err = mcr.ErrAuthFailed
+	}
+	if err != nil {
+		c.Close()
+		return err
+	}
+
+	if c.keepAlive > 0 {
+		go c.keepAliveLoop(closed)
+	}
+	return nil
+}
+
+// sends a command to the server and returns the server response
+func (c *Client) Command(cmd string) (string, error) {
+	return c.CommandContext(context.Background(), cmd)
+}
+
+// sends a command to the server and returns the response, joining responses split across packets. Cancelling
+// the context stops waiting for the response
+func (c *Client) CommandContext(ctx context.Context, cmd string) (string, error) {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+
+	c.mu.Lock()
+	conn, closed := c.connection, c.closed
+	if conn == nil {
+		c.mu.Unlock()
+		return "", mcr.ErrNotConnected
+	}
+	seq := c.sequence
+	c.sequence++
+	p := &pendingCommand{done: make(chan string, 1)}
+	c.pending[seq] = p
+	c.lastSent = time.Now()
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, seq)
+		c.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return await(ctx, conn, closed, encode(typeCommand, append([]byte{seq}, cmd...)), p.done, c.retransmit)
+}
+
+// closes the connection, a pending command returns ErrConnectionClosed
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connection == nil {
+		return nil
+	}
+	err := c.connection.Close()
+	c.connection = nil
+	return err
+}
+
+// writes the packet and resends it every interval until a reply arrives, the context ends, or the connection
+// closes. Replies to earlier copies of the packet are identical so the first one is used
+func await[T any](ctx context.Context, conn net.Conn, closed <-chan struct{}, packet []byte, reply <-chan T, interval time.Duration) (T, error) {
+	var zero T
+	var resend <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		resend = ticker.C
+	}
+	for {
+		_, err := conn.Write(packet)
+		if err != nil {
+			return zero, err
+		}
+
+		select {
+		case v := <-reply:
+			return v, nil
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-closed:
+			return zero, mcr.ErrConnectionClosed
+		case <-resend:
+		}
+	}
+}
+
+// sends an empty command whenever the connection has been idle for the keepalive period
+func (c *Client) keepAliveLoop(closed <-chan struct{}) {
+	ticker := time.NewTicker(c.keepAlive / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		idle := time.Since(c.lastSent)
+		c.mu.Unlock()
+		if idle >= c.keepAlive {
+			c.Command("")
+		}
+	}
+}
+
+// reads packets until the connection closes, dispatching login replies, command responses, and server messages
+func (c *Client) read(conn net.Conn, closed chan struct{}) {
+	defer close(closed)
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		typ, payload, ok := decode(buf[:n])
+		if !ok || len(payload) < 1 { //corrupt packets are dropped and recovered by retransmission
+			continue
+		}
+
+		switch typ {
+		case typeLogin:
+			c.mu.Lock()
+			select {
+			case c.login <- payload[0] == 0x01:
+			default:
+			}
+			c.mu.Unlock()
+		case typeCommand:
+			c.response(payload[0], payload[1:])
+		case typeMessage:
+			seq := payload[0]
+			conn.Write(encode(typeMessage, []byte{seq}))
+			c.mu.Lock()
+			duplicate := c.lastMessage == int(seq)
+			c.lastMessage = int(seq)
+			c.mu.Unlock()
+			if !duplicate && c.onMessage != nil {
+				c.onMessage(string(payload[1:]))
+			}
+		}
+	}
+}
+
+// adds a response packet to the pending command, responses split across packets start with a zero byte
+// followed by the packet count and index
+func (c *Client) response(seq byte, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[seq]
+	if !ok { //retransmitted response to a finished command
+		return
+	}
+
+	if len(data) < 3 || data[0] != 0x00 {
+		select {
+		case p.done <- string(data):
+		default:
+		}
+		return
+	}
+
+	total, index := int(data[1]), int(data[2])
+	if p.parts == nil {
+		if total == 0 {
+			return
+		}
+		p.parts = make([][]byte, total)
+	}
+	if total != len(p.parts) || index >= total || p.parts[index] != nil {
+		return
+	}
+	p.parts[index] = append([]byte{}, data[3:]...)
+	p.received++
+	if p.received == total {
+		var sb strings.Builder
+		for _, part := range p.parts {
+			sb.Write(part)
+		}
+		select {
+		case p.done <- sb.String():
+		default:
+		}
+	}
+}
+
+// builds a packet: "BE", crc32 of the rest of the packet, 0xff, type, payload
+func encode(typ byte, payload []byte) []byte {
+	body := append([]byte{0xff, typ}, payload...)
+	packet := binary.LittleEndian.AppendUint32([]byte{'B', 'E'}, crc32.ChecksumIEEE(body))
+	return append(packet, body...)
+}
+
+// verifies a packet and returns its type and payload
+func decode(packet []byte) (byte, []byte, bool) {
+	if len(packet) < 8 || packet[0] != 'B' || packet[1] != 'E' || packet[6] != 0xff {
+		return 0, nil, false
+	}
+	if binary.LittleEndian.Uint32(packet[2:6]) != crc32.ChecksumIEEE(packet[6:]) {
+		return 0, nil, false
+	}
+	return packet[7], packet[8:], true
+}
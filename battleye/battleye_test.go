@@ -0,0 +1,141 @@
+package battleye
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+// starts a udp server behaving like a BattlEye server. The first copy of every command is dropped, the players
+// command is answered in two packets, and a server message is sent twice after login
+func startTestServer(t *testing.T) (int, func() int) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var mu sync.Mutex
+	acks := 0
+	go func() {
+		buf := make([]byte, 1500)
+		seen := make(map[byte]bool)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			typ, payload, ok := decode(buf[:n])
+			if !ok {
+				continue
+			}
+
+			switch typ {
+			case typeLogin:
+				if string(payload) != "secret" {
+					conn.WriteTo(encode(typeLogin, []byte{0x00}), addr)
+					continue
+				}
+				conn.WriteTo(encode(typeLogin, []byte{0x01}), addr)
+				msg := encode(typeMessage, append([]byte{0}, "Player #1 Steve connected"...))
+				conn.WriteTo(msg, addr)
+				conn.WriteTo(msg, addr)
+			case typeMessage:
+				mu.Lock()
+				acks++
+				mu.Unlock()
+			case typeCommand:
+				seq, cmd := payload[0], string(payload[1:])
+				if !seen[seq] {
+					seen[seq] = true
+					continue
+				}
+				switch cmd {
+				case "players":
+					conn.WriteTo(encode(typeCommand, append([]byte{seq, 0x00, 2, 1}, "1 Steve"...)), addr)
+					conn.WriteTo(encode(typeCommand, append([]byte{seq, 0x00, 2, 0}, "Players on server:\n"...)), addr)
+				default:
+					conn.WriteTo(encode(typeCommand, append([]byte{seq}, cmd...)), addr)
+				}
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return acks
+	}
+}
+
+// testing login, retransmission, split responses, and server messages
+func TestClient(t *testing.T) {
+	port, acks := startTestServer(t)
+	var mu sync.Mutex
+	var messages []string
+	c := NewClient("127.0.0.1", WithPort(port), WithRetransmit(time.Millisecond*20), WithMessageHandler(func(m string) {
+		mu.Lock()
+		messages = append(messages, m)
+		mu.Unlock()
+	}))
+
+	err := c.Connect("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if !errors.Is(c.Connect("secret"), mcr.ErrAlreadyConnected) {
+		t.Fatal("expected already connected")
+	}
+
+	res, err := c.Command("players")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "Players on server:\n1 Steve" {
+		t.Fatalf("unexpected response %q", res)
+	}
+	res, err = c.Command("say -1 hello")
+	if err != nil || res != "say -1 hello" {
+		t.Fatalf("unexpected response %q %v", res, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(messages) != 1 || messages[0] != "Player #1 Steve connected" {
+		t.Fatalf("expected one server message, got %q", messages)
+	}
+	if acks() != 2 {
+		t.Fatalf("expected both message copies to be acknowledged, got %d", acks())
+	}
+}
+
+// testing rejected passwords and commands sent before connecting
+func TestAuthFailed(t *testing.T) {
+	port, _ := startTestServer(t)
+	c := NewClient("127.0.0.1", WithPort(port))
+	if !errors.Is(c.Connect("wrong"), mcr.ErrAuthFailed) {
+		t.Fatal("expected auth failure")
+	}
+	_, err := c.Command("players")
+	if !errors.Is(err, mcr.ErrNotConnected) {
+		t.Fatalf("expected not connected, got %v", err)
+	}
+}
+
+// testing packets with a bad checksum are rejected
+func TestDecode(t *testing.T) {
+	packet := encode(typeCommand, []byte{0, 'h', 'i'})
+	typ, payload, ok := decode(packet)
+	if !ok || typ != typeCommand || string(payload) != "\x00hi" {
+		t.Fatal("valid packet was not decoded")
+	}
+	packet[len(packet)-1] = 'x'
+	if _, _, ok = decode(packet); ok {
+		t.Fatal("corrupt packet was accepted")
+	}
+}
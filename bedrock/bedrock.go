@@ -0,0 +1,173 @@
+// Package bedrock implements the RakNet unconnected ping used by Bedrock Edition clients to list servers,
+// returning the motd, version, and player counts of a Bedrock server without credentials
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jake-young-dev/mcr/internal/ctxerr"
+)
+
+const (
+	//default values
+	DefaultPort    = 19132
+	DefaultTimeout = time.Second * 5
+
+	//packet ids
+	idUnconnectedPing = 0x01
+	idUnconnectedPong = 0x1c
+)
+
+// offline message id included in every unconnected packet
+var magic = []byte{0x00, 0xff, 0xff, 0x00, 0xfe, 0xfe, 0xfe, 0xfe, 0xfd, 0xfd, 0xfd, 0xfd, 0x12, 0x34, 0x56, 0x78}
+
+// returned when the server reply cannot be parsed
+var ErrMalformedResponse = errors.New("malformed pong")
+
+// server status advertised in the pong, fields after MaxPlayers are missing on older servers
+type Pong struct {
+	Edition    string //MCPE or MCEE for education edition
+	MOTD       string
+	Protocol   int
+	Version    string
+	Online     int
+	MaxPlayers int
+	ServerID   string
+	LevelName  string
+	GameMode   string
+	PortV4     int
+	PortV6     int
+	Raw        string        //semicolon separated status sent by the server
+	Latency    time.Duration //round trip of the ping
+}
+
+// bedrock ping client, each request uses a new socket so the client is safe for concurrent use
+type Client struct {
+	address string
+	port    int
+	timeout time.Duration
+}
+
+// client option func skeleton
+type Option func(c *Client)
+
+// option to allow for custom port values
+func WithPort(port int) Option {
+	return func(c *Client) {
+		c.port = port
+	}
+}
+
+// option to allow for custom timeouts
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// creates a new bedrock ping client configured with the supplied options
+func NewClient(addr string, opts ...Option) *Client {
+	c := &Client{
+		address: addr,
+		port:    DefaultPort,
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// sends an unconnected ping and parses the server status from the pong
+func (c *Client) Ping(ctx context.Context) (*Pong, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", net.JoinHostPort(c.address, strconv.Itoa(c.port)))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	start := time.Now()
+	sent := uint64(start.UnixMilli())
+	req := binary.BigEndian.AppendUint64([]byte{idUnconnectedPing}, sent)
+	req = append(req, magic...)
+	req = binary.BigEndian.AppendUint64(req, rand.Uint64()) //client guid
+	_, err = conn.Write(req)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err)
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err)
+		}
+		res := buf[:n]
+		//id, echoed time, server guid, magic, string length
+		if n < 35 || res[0] != idUnconnectedPong || binary.BigEndian.Uint64(res[1:9]) != sent {
+			continue //not the reply to this ping
+		}
+		if !bytes.Equal(res[17:33], magic) {
+			return nil, fmt.Errorf("%w: missing offline message id", ErrMalformedResponse)
+		}
+		size := int(binary.BigEndian.Uint16(res[33:35]))
+		if size > n-35 {
+			return nil, fmt.Errorf("%w: truncated status", ErrMalformedResponse)
+		}
+		pong, err := parsePong(string(res[35 : 35+size]))
+		if err != nil {
+			return nil, err
+		}
+		pong.Latency = time.Since(start)
+		return pong, nil
+	}
+}
+
+// parses the semicolon separated status string
+func parsePong(raw string) (*Pong, error) {
+	fields := strings.Split(raw, ";")
+	if len(fields) < 6 {
+		return nil, fmt.Errorf("%w: %d status fields", ErrMalformedResponse, len(fields))
+	}
+	field := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+	number := func(i int) int {
+		n, _ := strconv.Atoi(field(i))
+		return n
+	}
+
+	return &Pong{
+		Edition:    fields[0],
+		MOTD:       fields[1],
+		Protocol:   number(2),
+		Version:    fields[3],
+		Online:     number(4),
+		MaxPlayers: number(5),
+		ServerID:   field(6),
+		LevelName:  field(7),
+		GameMode:   field(8),
+		PortV4:     number(10),
+		PortV6:     number(11),
+		Raw:        raw,
+	}, nil
+}
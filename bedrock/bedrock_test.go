@@ -0,0 +1,91 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+const testStatus = "MCPE;Dedicated Server;712;1.21.20;2;10;13253860892328930865;Bedrock level;Survival;1;19132;19133;"
+
+// starts a udp server answering unconnected pings like a dedicated server
+func startTestServer(t *testing.T) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n != 33 || buf[0] != idUnconnectedPing {
+				continue
+			}
+			res := append([]byte{idUnconnectedPong}, buf[1:9]...)
+			res = binary.BigEndian.AppendUint64(res, 13253860892328930865)
+			res = append(res, magic...)
+			res = binary.BigEndian.AppendUint16(res, uint16(len(testStatus)))
+			res = append(res, testStatus...)
+			conn.WriteTo(res, addr)
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// testing the ping and pong parsing
+func TestPing(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t)))
+	pong, err := c.Ping(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pong.MOTD != "Dedicated Server" || pong.Protocol != 712 || pong.Version != "1.21.20" {
+		t.Fatalf("unexpected pong %+v", pong)
+	}
+	if pong.Online != 2 || pong.MaxPlayers != 10 || pong.GameMode != "Survival" || pong.PortV6 != 19133 {
+		t.Fatalf("unexpected pong %+v", pong)
+	}
+	if pong.Latency <= 0 {
+		t.Fatal("expected a latency")
+	}
+}
+
+// testing short status strings from older servers
+func TestParsePong(t *testing.T) {
+	pong, err := parsePong("MCPE;Old Server;390;1.14.60;0;20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pong.MaxPlayers != 20 || pong.ServerID != "" {
+		t.Fatalf("unexpected pong %+v", pong)
+	}
+
+	_, err = parsePong("MCPE;broken")
+	if !errors.Is(err, ErrMalformedResponse) {
+		t.Fatalf("expected malformed response, got %v", err)
+	}
+}
+
+// testing unanswered pings give up once the timeout passes
+func TestTimeout(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	c := NewClient("127.0.0.1", WithPort(conn.LocalAddr().(*net.UDPAddr).Port), WithTimeout(time.Millisecond*100))
+	_, err = c.Ping(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
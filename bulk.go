@@ -0,0 +1,75 @@
+package mcr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// result of a bulk player operation for a single player
+type BulkResult struct {
+	Response string  //server response to the player command
+	Outcome  Outcome //classified server response
+	Err      error   //transport or validation error, the command may not have reached the server
+}
+
+// returns true if the command reached the server and the server reported success
+func (r BulkResult) OK() bool {
+	return r.Err == nil && r.Outcome == OutcomeSuccess
+}
+
+// kicks each player returning the result for every name
+func (c *Client) BulkKick(names []string) map[string]BulkResult {
+	return c.BulkKickContext(context.Background(), names)
+}
+
+// kicks each player returning the result for every name, see BulkContext
+func (c *Client) BulkKickContext(ctx context.Context, names []string) map[string]BulkResult {
+	return c.BulkContext(ctx, "kick", names)
+}
+
+// bans each player returning the result for every name
+func (c *Client) BulkBan(names []string) map[string]BulkResult {
+	return c.BulkBanContext(context.Background(), names)
+}
+
+// bans each player returning the result for every name, see BulkContext
+func (c *Client) BulkBanContext(ctx context.Context, names []string) map[string]BulkResult {
+	return c.BulkContext(ctx, "ban", names)
+}
+
+// adds each player to the whitelist returning the result for every name
+func (c *Client) BulkWhitelist(names []string) map[string]BulkResult {
+	return c.BulkWhitelistContext(context.Background(), names)
+}
+
+// adds each player to the whitelist returning the result for every name, see BulkContext
+func (c *Client) BulkWhitelistContext(ctx context.Context, names []string) map[string]BulkResult {
+	return c.BulkContext(ctx, "whitelist add", names)
+}
+
+// runs the command followed by each player name, returning the result for every name. Commands share the
+// client connection so they are sent one at a time, a failure for one player does not stop the remaining
+// players from being processed. Once the context ends the pending command is interrupted and the remaining
+// players are given the context error without being sent
+func (c *Client) BulkContext(ctx context.Context, cmd string, names []string) map[string]BulkResult {
+	results := make(map[string]BulkResult, len(names))
+	for _, name := range names {
+		if name == "" || strings.ContainsAny(name, " \t\r\n") {
+			results[name] = BulkResult{Err: fmt.Errorf("%w: invalid player name %q", ErrInvalidArgument, name)}
+			continue
+		}
+		if ctx.Err() != nil {
+			results[name] = BulkResult{Err: ctx.Err()}
+			continue
+		}
+
+		res, err := c.CommandContext(ctx, cmd+" "+name)
+		if err != nil {
+			results[name] = BulkResult{Err: err}
+			continue
+		}
+		results[name] = BulkResult{Response: res, Outcome: Classify(res)}
+	}
+	return results
+}
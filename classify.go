@@ -0,0 +1,74 @@
+package mcr
+
+import (
+	"strings"
+)
+
+// semantic outcome of a command derived from the server response text
+type Outcome int
+
+const (
+	OutcomeSuccess Outcome = iota
+	OutcomeUnknownCommand
+	OutcomeNoPermission
+	OutcomePlayerNotFound
+	OutcomeSyntaxError
+)
+
+// vanilla and Bukkit error messages for each outcome. Servers send these at the start of the response so
+// markers are only matched at the start of a line, keeping command output that merely mentions the same words
+// (player chat, item names, log lines) from being classified as an error. Markers are matched
+// case-insensitively in order so more specific markers must come first
+var outcomeMarkers = []struct {
+	outcome Outcome
+	marker  string
+}{
+	{OutcomeUnknownCommand, "unknown or incomplete command"},
+	{OutcomeUnknownCommand, "unknown command"},
+	{OutcomeNoPermission, "i'm sorry, but you do not have permission"},
+	{OutcomeNoPermission, "you do not have permission"},
+	{OutcomeNoPermission, "you don't have permission"},
+	{OutcomePlayerNotFound, "no player was found"},
+	{OutcomePlayerNotFound, "player not found"},
+	{OutcomePlayerNotFound, "that player does not exist"},
+	{OutcomeSyntaxError, "incorrect argument for command"},
+}
+
+// vanilla marks the position of a parse error with this suffix, parse errors have no fixed prefix
+const syntaxErrorSuffix = "<--[here]"
+
+// maps a command response to a semantic outcome, responses that do not match a known error message are
+// considered successful
+func Classify(body string) Outcome {
+	lines := strings.Split(strings.ToLower(formatPattern.ReplaceAllString(body, "")), "\n")
+	for _, m := range outcomeMarkers {
+		for _, line := range lines {
+			if strings.HasPrefix(strings.TrimSpace(line), m.marker) {
+				return m.outcome
+			}
+		}
+	}
+	for _, line := range lines {
+		if strings.HasSuffix(strings.TrimSpace(line), syntaxErrorSuffix) {
+			return OutcomeSyntaxError
+		}
+	}
+	return OutcomeSuccess
+}
+
+// returns the name of the outcome
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "Success"
+	case OutcomeUnknownCommand:
+		return "UnknownCommand"
+	case OutcomeNoPermission:
+		return "NoPermission"
+	case OutcomePlayerNotFound:
+		return "PlayerNotFound"
+	case OutcomeSyntaxError:
+		return "SyntaxError"
+	}
+	return "Unknown"
+}
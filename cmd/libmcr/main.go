@@ -0,0 +1,119 @@
+// Command libmcr exports a minimal C ABI around the RCon client so tools written in other languages can reuse
+// it. Build the shared library and header with:
+//
+//	go build -buildmode=c-shared -o libmcr.so ./cmd/libmcr
+//
+// Strings returned by the library are allocated with malloc and must be released with mcr_free. Functions
+// that can fail set *err to an error message and return 0 or NULL
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+var (
+	mu         sync.Mutex //guards the handle table only, commands lock their own connection
+	clients    = make(map[C.longlong]*connection)
+	nextHandle C.longlong
+)
+
+// client behind a handle, commands on a connection cannot be interleaved so each holds its own lock
+type connection struct {
+	mu     sync.Mutex
+	client *mcr.Client
+}
+
+// returns the connection for a handle
+func lookup(handle C.longlong) (*connection, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	conn, ok := clients[handle]
+	return conn, ok
+}
+
+func main() {}
+
+// connects and authenticates to the server returning a handle for the other calls, 0 is returned on failure
+//
+//export mcr_connect
+func mcr_connect(address *C.char, port C.int, password *C.char, err **C.char) C.longlong {
+	client := mcr.NewClient(C.GoString(address), mcr.WithPort(int(port)))
+	e := client.Connect(C.GoString(password))
+	if e != nil {
+		client.Close()
+		setError(err, e)
+		return 0
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	nextHandle++
+	clients[nextHandle] = &connection{client: client}
+	return nextHandle
+}
+
+// sends a command and returns the server response, NULL is returned on failure
+//
+//export mcr_command
+func mcr_command(handle C.longlong, cmd *C.char, err **C.char) *C.char {
+	conn, ok := lookup(handle)
+	if !ok {
+		setErrorString(err, "unknown client handle")
+		return nil
+	}
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	res, e := conn.client.Command(C.GoString(cmd))
+	if e != nil {
+		setError(err, e)
+		return nil
+	}
+	return C.CString(res)
+}
+
+// closes the connection and releases the handle, returns 0 on success and -1 on failure
+//
+//export mcr_close
+func mcr_close(handle C.longlong) C.int {
+	mu.Lock()
+	conn, ok := clients[handle]
+	delete(clients, handle)
+	mu.Unlock()
+	if !ok {
+		return -1
+	}
+
+	conn.mu.Lock() //waits for a running command to finish
+	defer conn.mu.Unlock()
+	if conn.client.Close() != nil {
+		return -1
+	}
+	return 0
+}
+
+// releases a string returned by the library
+//
+//export mcr_free
+func mcr_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// sets the error out parameter when the caller supplied one
+func setError(err **C.char, e error) {
+	setErrorString(err, e.Error())
+}
+
+func setErrorString(err **C.char, msg string) {
+	if err != nil {
+		*err = C.CString(msg)
+	}
+}
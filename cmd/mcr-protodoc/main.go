@@ -0,0 +1,71 @@
+// Command mcr-protodoc renders the packet layout, packet types, and quirks implemented by mcr as JSON for
+// tooling or markdown tables for humans, keeping alternative implementations in sync with the Go source
+//
+//	mcr-protodoc -format markdown > PROTOCOL.md
+//	mcr-protodoc -format json > protocol.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+func main() {
+	format := flag.String("format", "markdown", "output format, json or markdown")
+	flag.Parse()
+
+	doc := mcr.DescribeProtocol()
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(doc)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "markdown":
+		writeMarkdown(os.Stdout, doc)
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+}
+
+// writes the document as markdown tables
+func writeMarkdown(w io.Writer, doc mcr.ProtocolDoc) {
+	fmt.Fprintf(w, "# %s RCon protocol\n\n", strings.ToUpper(doc.Protocol[:1])+doc.Protocol[1:])
+	fmt.Fprintf(w, "Integers are %s. Responses longer than %d body bytes are split across packets. ", doc.ByteOrder, doc.MaxFragmentSize)
+	fmt.Fprintf(w, "The server replies to a rejected password with request id %d.\n\n", doc.FailureID)
+
+	fmt.Fprintln(w, "## Packet layout")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Field | Type | Size | Description |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- |")
+	for _, f := range doc.Fields {
+		fmt.Fprintf(w, "| %s | %s | %s | %s |\n", f.Name, f.Type, f.Size, f.Description)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "## Packet types")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Name | Value | Direction |")
+	fmt.Fprintln(w, "| --- | --- | --- |")
+	for _, t := range doc.PacketTypes {
+		fmt.Fprintf(w, "| %s | %d | %s |\n", t.Name, t.Value, t.Direction)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "## Quirks")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Name | Value | Description | Fingerprints |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- |")
+	for _, q := range doc.Quirks {
+		fmt.Fprintf(w, "| %s | %d | %s | %s |\n", q.Name, uint32(q.Value), q.Description, strings.Join(q.Fingerprints, ", "))
+	}
+}
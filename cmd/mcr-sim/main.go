@@ -0,0 +1,59 @@
+// Command mcr-sim runs a deterministic RCon server that behaves like a vanilla Minecraft server, for running
+// CI against projects that use RCon without a real server
+//
+//	mcr-sim -addr 127.0.0.1:25575 -password secret -players Steve,Alex -latency 50ms -encoding latin1
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/jake-young-dev/mcr"
+	"github.com/jake-young-dev/mcr/sim"
+)
+
+// response body encodings accepted by the encoding flag
+var encodings = map[string]mcr.Encoding{
+	"utf8":    mcr.EncodingUTF8,
+	"latin1":  mcr.EncodingLatin1,
+	"escaped": mcr.EncodingEscaped,
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:25575", "address to listen on")
+	password := flag.String("password", "", "password required to authenticate, empty accepts any password")
+	fragment := flag.Int("fragment", sim.DefaultFragmentSize, "maximum body bytes per response packet")
+	latency := flag.Duration("latency", 0, "delay before each response")
+	players := flag.String("players", "", "comma separated players reported as online")
+	encoding := flag.String("encoding", "utf8", "response body encoding: utf8, latin1, or escaped")
+	flag.Parse()
+
+	enc, ok := encodings[*encoding]
+	if !ok {
+		log.Fatalf("unknown encoding %q", *encoding)
+	}
+
+	s := &sim.Server{
+		Password:     *password,
+		FragmentSize: *fragment,
+		Latency:      *latency,
+		Encoding:     enc,
+	}
+	if *players != "" {
+		s.Players = strings.Split(*players, ",")
+	}
+
+	err := s.Listen(*addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("simulated rcon server listening on %s", s.Addr())
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+	s.Close()
+}
@@ -0,0 +1,106 @@
+//go:build js && wasm
+
+// Command mcr-wasm exposes the RCon client to javascript through a global mcr object. Build it with
+// GOOS=js GOARCH=wasm and load it using the wasm_exec.js file shipped with Go. Connections go through a
+// websocket to tcp relay:
+//
+//	await mcr.connect("wss://relay.example/rcon", "password")
+//	const players = await mcr.command("list")
+//	await mcr.close()
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall/js"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+var (
+	mu              sync.Mutex //guards client, promises run on separate goroutines
+	client          *mcr.Client
+	errNotConnected = errors.New("mcr.connect must be called before commands can be run")
+)
+
+func main() {
+	js.Global().Set("mcr", js.ValueOf(map[string]any{
+		"connect": js.FuncOf(connect),
+		"command": js.FuncOf(command),
+		"close":   js.FuncOf(closeClient),
+	}))
+	select {} //keep the bindings alive
+}
+
+// connect(relayURL, password) connects and authenticates through the relay
+func connect(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return rejected(fmt.Errorf("mcr.connect expects 2 arguments, got %d", len(args)))
+	}
+	url, password := args[0].String(), args[1].String()
+	return promise(func() (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if client != nil {
+			client.Close()
+		}
+		client = mcr.NewClient("relay", mcr.WithWebSocketProxy(url))
+		return nil, client.Connect(password)
+	})
+}
+
+// command(cmd) resolves with the server response
+func command(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return rejected(errors.New("mcr.command expects a command"))
+	}
+	cmd := args[0].String()
+	return promise(func() (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if client == nil {
+			return nil, errNotConnected
+		}
+		return client.Command(cmd)
+	})
+}
+
+// close() closes the connection
+func closeClient(this js.Value, args []js.Value) any {
+	return promise(func() (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if client == nil {
+			return nil, nil
+		}
+		err := client.Close()
+		client = nil
+		return nil, err
+	})
+}
+
+// runs fn on a new goroutine and returns a promise settled with its result, blocking work must not run on the
+// javascript event loop
+func promise(fn func() (any, error)) js.Value {
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) any {
+		resolve, reject := args[0], args[1]
+		go func() {
+			defer executor.Release()
+			res, err := fn()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(res)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+// returns a promise rejected with err, used for invalid arguments so callers see the error through await
+func rejected(err error) js.Value {
+	return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New(err.Error()))
+}
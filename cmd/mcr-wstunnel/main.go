@@ -0,0 +1,42 @@
+// Command mcr-wstunnel relays websocket connections to an RCon server, for clients that cannot open raw tcp
+// connections such as browsers using the WithWebSocketProxy option
+//
+//	mcr-wstunnel -listen :8080 -path /rcon -target 127.0.0.1:25575
+//	mcr-wstunnel -listen :8443 -target 127.0.0.1:25575 -cert cert.pem -key key.pem
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/jake-young-dev/mcr/wstunnel"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:8080", "address to listen on")
+	path := flag.String("path", "/", "http path accepting websocket connections")
+	target := flag.String("target", "127.0.0.1:25575", "address of the rcon server")
+	cert := flag.String("cert", "", "tls certificate file, serves wss when set with -key")
+	key := flag.String("key", "", "tls key file")
+	dialTimeout := flag.Duration("dial-timeout", wstunnel.DefaultDialTimeout, "time allowed to connect to the rcon server")
+	flag.Parse()
+
+	mux := http.NewServeMux()
+	mux.Handle(*path, &wstunnel.Handler{
+		Target:      *target,
+		DialTimeout: *dialTimeout,
+		Logger:      log.New(os.Stderr, "", log.LstdFlags),
+	})
+
+	var err error
+	if *cert != "" && *key != "" {
+		log.Printf("relaying wss://%s%s to %s", *listen, *path, *target)
+		err = http.ListenAndServeTLS(*listen, *cert, *key, mux)
+	} else {
+		log.Printf("relaying ws://%s%s to %s", *listen, *path, *target)
+		err = http.ListenAndServe(*listen, mux)
+	}
+	log.Fatal(err)
+}
@@ -0,0 +1,186 @@
+package mcr
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// immutable snapshot of the client configuration. Options holding functions (dialers, transports, proxies,
+// confirmation and duplicate callbacks, password candidates, slow log and session sinks) and learned state
+// such as adaptive latencies are not included, supply them again after importing a snapshot
+type Config struct {
+	Address           string             `json:"address"`
+	Port              int                `json:"port"`
+	Timeout           time.Duration      `json:"timeout"`
+	Cap               int32              `json:"cap"`
+	NoDelay           bool               `json:"no_delay"`
+	ReadTimeout       time.Duration      `json:"read_timeout"`
+	Fingerprint       string             `json:"fingerprint,omitempty"`
+	Quirks            Quirk              `json:"quirks"`
+	QuirksSet         bool               `json:"quirks_set"`
+	Encoding          Encoding           `json:"encoding"`
+	Reassembly        ReassemblyStrategy `json:"reassembly"`
+	FragmentTimeout   time.Duration      `json:"fragment_timeout"`
+	DrainTimeout      time.Duration      `json:"drain_timeout"`
+	DrainLimit        int                `json:"drain_limit"`
+	MaxResponseSize   int                `json:"max_response_size"`
+	Labels            map[string]string  `json:"labels,omitempty"`
+	ReadOnly          bool               `json:"read_only"`
+	Conformance       bool               `json:"conformance"`
+	StrictCorrelation bool               `json:"strict_correlation"`
+	IdentityFormat    string             `json:"identity_format,omitempty"`
+	SRVLookup         bool               `json:"srv_lookup"`
+	Trim              TrimPolicy         `json:"trim"`
+	KeepAlive         time.Duration      `json:"keep_alive"`
+	Sanitize          *SanitizePolicy    `json:"sanitize,omitempty"`
+	Maintenance       *MaintenancePolicy `json:"maintenance,omitempty"`
+	Duplicates        *DuplicatePolicy   `json:"duplicates,omitempty"` //the Confirm callback is not exported
+	LocalAddr         string             `json:"local_addr,omitempty"`
+	RequirePassword   bool               `json:"require_password"`
+	ReconnectMin      time.Duration      `json:"reconnect_min"`
+	ReconnectMax      time.Duration      `json:"reconnect_max"`
+	ReconnectAttempts int                `json:"reconnect_attempts"` //zero when automatic reconnects are disabled
+	Adaptive          bool               `json:"adaptive"`
+	AdaptiveMin       time.Duration      `json:"adaptive_min"`
+	AdaptiveMax       time.Duration      `json:"adaptive_max"`
+	FullTrace         bool               `json:"full_trace"`
+}
+
+// returns a snapshot of the client configuration, changes to the snapshot do not affect the client
+func (c *Client) Config() Config {
+	cfg := Config{
+		Address:           c.address,
+		Port:              c.port,
+		Timeout:           c.timeout,
+		Cap:               c.cap,
+		NoDelay:           c.noDelay,
+		ReadTimeout:       c.readTimeout,
+		Fingerprint:       c.fingerprint,
+		Quirks:            c.quirks,
+		QuirksSet:         c.quirksSet,
+		Encoding:          c.encoding,
+		Reassembly:        c.reassembly,
+		FragmentTimeout:   c.fragmentTimeout,
+		DrainTimeout:      c.drainTimeout,
+		DrainLimit:        c.drainLimit,
+		MaxResponseSize:   c.maxResponseSize,
+		Labels:            c.Labels(),
+		ReadOnly:          c.readOnly,
+		Conformance:       c.conformance,
+		StrictCorrelation: c.strict,
+		IdentityFormat:    c.identityFormat,
+		SRVLookup:         c.srvLookup,
+		Trim:              c.trim,
+		KeepAlive:         c.keepAlive,
+		Sanitize:          c.sanitizePolicy(),
+		RequirePassword:   c.requirePassword,
+		FullTrace:         c.trace.full,
+	}
+	if c.maintenance != nil {
+		policy := *c.maintenance
+		policy.Windows = append([]*MaintenanceWindow(nil), policy.Windows...)
+		cfg.Maintenance = &policy
+	}
+	if c.duplicates != nil {
+		policy := c.duplicates.policy
+		cfg.Duplicates = &policy
+	}
+	if c.localAddr != nil {
+		cfg.LocalAddr = c.localAddr.String()
+	}
+	if c.reconnect != nil {
+		cfg.ReconnectMin, cfg.ReconnectMax, cfg.ReconnectAttempts = c.reconnect.min, c.reconnect.max, c.reconnect.attempts
+	}
+	if c.adaptive != nil {
+		cfg.Adaptive, cfg.AdaptiveMin, cfg.AdaptiveMax = true, c.adaptive.min, c.adaptive.max
+	}
+	return cfg
+}
+
+// applies options to a client that has not connected yet, an error is returned if the client is connected
+// since most options only take effect when the connection is made
+func (c *Client) Apply(opts ...Option) error {
+	if c.connection != nil {
+		return ErrAlreadyConnected
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	return nil
+}
+
+// replaces the client configuration with the snapshot, an error is returned if the local address is invalid
+func (c *Client) setConfig(cfg Config) error {
+	var localAddr net.Addr
+	if cfg.LocalAddr != "" {
+		addr, err := net.ResolveTCPAddr(Protocol, cfg.LocalAddr)
+		if err != nil {
+			return fmt.Errorf("%w: local address %q: %w", ErrInvalidArgument, cfg.LocalAddr, err)
+		}
+		localAddr = addr
+	}
+
+	c.address = cfg.Address
+	c.port = cfg.Port
+	c.timeout = cfg.Timeout
+	c.cap = cfg.Cap
+	c.noDelay = cfg.NoDelay
+	c.readTimeout = cfg.ReadTimeout
+	c.fingerprint = cfg.Fingerprint
+	c.quirks = cfg.Quirks
+	c.quirksSet = cfg.QuirksSet
+	c.encoding = cfg.Encoding
+	c.reassembly = cfg.Reassembly
+	c.fragmentTimeout = cfg.FragmentTimeout
+	c.drainTimeout = cfg.DrainTimeout
+	c.drainLimit = cfg.DrainLimit
+	c.maxResponseSize = cfg.MaxResponseSize
+	if c.maxResponseSize < 1 { //snapshots exported before the limit existed
+		c.maxResponseSize = DefaultMaxResponseSize
+	}
+	c.labels = cfg.Labels
+	c.readOnly = cfg.ReadOnly
+	c.conformance = cfg.Conformance
+	c.strict = cfg.StrictCorrelation
+	c.identityFormat = cfg.IdentityFormat
+	c.srvLookup = cfg.SRVLookup
+	c.trim = cfg.Trim
+	c.keepAlive = cfg.KeepAlive
+	c.sanitize = nil
+	if cfg.Sanitize != nil {
+		policy := *cfg.Sanitize
+		c.sanitize = &policy
+	}
+	c.maintenance = nil
+	if cfg.Maintenance != nil {
+		policy := *cfg.Maintenance
+		c.maintenance = &policy
+	}
+	c.duplicates = nil
+	if cfg.Duplicates != nil {
+		WithDuplicateSuppression(*cfg.Duplicates)(c)
+	}
+	c.localAddr = localAddr
+	c.requirePassword = cfg.RequirePassword
+	c.reconnect = nil
+	if cfg.ReconnectAttempts > 0 {
+		WithAutoReconnect(cfg.ReconnectMin, cfg.ReconnectMax, cfg.ReconnectAttempts)(c)
+	}
+	c.adaptive = nil
+	if cfg.Adaptive {
+		WithAdaptiveTimeouts(cfg.AdaptiveMin, cfg.AdaptiveMax)(c)
+	}
+	c.trace.full = cfg.FullTrace
+	return nil
+}
+
+// returns a copy of the sanitization policy so snapshots cannot change the client
+func (c *Client) sanitizePolicy() *SanitizePolicy {
+	if c.sanitize == nil {
+		return nil
+	}
+	policy := *c.sanitize
+	return &policy
+}
@@ -0,0 +1,53 @@
+package mcr
+
+import (
+	"regexp"
+	"strings"
+)
+
+// commands that stop the server or remove access, suitable for use as ConfirmationPolicy patterns. The
+// patterns ignore case, leading slashes, the minecraft namespace, and also match commands run by execute
+var DangerousCommands = []*regexp.Regexp{
+	dangerousCommand(`stop\b`),
+	dangerousCommand(`(ban|ban-ip|deop)\b`),
+	dangerousCommand(`whitelist\s+(off|remove)\b`),
+}
+
+// matches the command at the start of the text or after the run keyword of an execute chain
+func dangerousCommand(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^[\s/]*|\brun\s+/?)(minecraft:)?` + pattern)
+}
+
+// finds the commands nested in execute chains
+var runPattern = regexp.MustCompile(`(?i)\brun\s+`)
+
+// requires commands matching any of the patterns to be approved by the Confirm callback before they are sent
+type ConfirmationPolicy struct {
+	Patterns []*regexp.Regexp      //commands matching any pattern require confirmation
+	Confirm  func(cmd string) bool //returns true to send the command, a nil callback rejects all matches
+}
+
+// returns true if the command or a command run by an execute chain matches one of the policy patterns,
+// leading slashes and whitespace are ignored
+func (p *ConfirmationPolicy) requires(cmd string) bool {
+	cmds := []string{strings.TrimLeft(cmd, "/ \t")}
+	for _, loc := range runPattern.FindAllStringIndex(cmd, -1) {
+		cmds = append(cmds, strings.TrimLeft(cmd[loc[1]:], "/ \t"))
+	}
+	for _, pattern := range p.Patterns {
+		for _, c := range cmds {
+			if pattern.MatchString(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// returns true if the command can be sent under the policy
+func (p *ConfirmationPolicy) approve(cmd string) bool {
+	if p == nil || !p.requires(cmd) {
+		return true
+	}
+	return p.Confirm != nil && p.Confirm(cmd)
+}
@@ -0,0 +1,90 @@
+package mcr
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// specification the conformance rules are taken from
+const SpecURL = "https://developer.valvesoftware.com/wiki/Source_RCON_Protocol"
+
+// describes a response that deviates from the RCon specification, matches ErrNonConformant with errors.Is
+type ConformanceError struct {
+	Section string //anchor of the specification section stating the rule
+	Rule    string //rule that was violated
+	Detail  string //what the server sent
+}
+
+func (e *ConformanceError) Error() string {
+	return fmt.Sprintf("%s: %s, %s (see %s#%s)", ErrNonConformant, e.Rule, e.Detail, SpecURL, e.Section)
+}
+
+func (e *ConformanceError) Is(target error) bool {
+	return target == ErrNonConformant
+}
+
+// option to reject any response that deviates from the RCon specification with a ConformanceError citing the
+// violated rule, intended for developers validating a server implementation. Deviations excused by quirks set
+// with WithQuirks or WithFingerprint are not reported
+func WithConformance() Option {
+	return func(cn *Client) {
+		cn.conformance = true
+	}
+}
+
+// checks the Size header before the body is read
+func checkSize(size int32) error {
+	switch {
+	case size < PacketRequestSize:
+		return &ConformanceError{
+			Section: "Packet_Size",
+			Rule:    fmt.Sprintf("a packet holds at least %d bytes after the Size field, the ID, Type, and two null terminators", PacketRequestSize),
+			Detail:  fmt.Sprintf("got Size %d", size),
+		}
+	case size > MaxFragmentSize+PacketRequestSize:
+		return &ConformanceError{
+			Section: "Multiple-packet_Responses",
+			Rule:    fmt.Sprintf("response bodies larger than %d bytes must be split across multiple packets", MaxFragmentSize),
+			Detail:  fmt.Sprintf("got Size %d", size),
+		}
+	}
+	return nil
+}
+
+// checks the body is a null terminated string followed by an empty string, payload includes the padding
+func checkBody(payload []byte) error {
+	if len(payload) < PacketPaddingSize || payload[len(payload)-1] != 0 || payload[len(payload)-2] != 0 {
+		return &ConformanceError{
+			Section: "Empty_String",
+			Rule:    "the body must be null terminated and followed by an empty string",
+			Detail:  fmt.Sprintf("packet ends with % x", payload[max(0, len(payload)-PacketPaddingSize):]),
+		}
+	}
+	if i := bytes.IndexByte(payload[:len(payload)-PacketPaddingSize], 0); i >= 0 {
+		return &ConformanceError{
+			Section: "Packet_Body",
+			Rule:    "the body is a null terminated string and cannot contain null bytes",
+			Detail:  fmt.Sprintf("null byte at offset %d", i),
+		}
+	}
+	return nil
+}
+
+// checks a response answers the request it was read for
+func checkResponse(res *Response, id int32, want PacketType) error {
+	if res.Type != want {
+		return &ConformanceError{
+			Section: "Packet_Type",
+			Rule:    fmt.Sprintf("the server must reply with SERVERDATA_%s", SourcePacketTypes.Response(want)),
+			Detail:  fmt.Sprintf("got type %d", int32(res.Type)),
+		}
+	}
+	if res.RequestID != id && !(want == AuthResponsePacket && res.RequestID == FailurePacket) {
+		return &ConformanceError{
+			Section: "Packet_ID",
+			Rule:    "the response ID must match the ID of the request",
+			Detail:  fmt.Sprintf("sent ID %d, got ID %d", id, res.RequestID),
+		}
+	}
+	return nil
+}
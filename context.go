@@ -0,0 +1,51 @@
+package mcr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// binds the context to the connection for the duration of an operation. The context deadline bounds writes
+// and reads, cancelling the context expires the connection deadlines to unblock pending i/o. The returned
+// func must be called once the operation completes
+func (c *Client) bind(ctx context.Context) func() {
+	//the connection is captured so the cancellation callback never touches a connection that was closed or
+	//replaced by a reconnect after the operation finished
+	conn := c.connection
+	c.ctx = ctx
+	deadline, _ := ctx.Deadline()
+	conn.SetWriteDeadline(deadline)
+
+	//the read loop checks ctx.Err after setting each read deadline so a cancellation racing a new deadline
+	//is never lost
+	done := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+		close(done)
+	})
+	return func() {
+		if !stop() { //the callback has started, wait so it cannot expire the deadline after it is cleared
+			<-done
+		}
+		c.ctx = nil
+		conn.SetWriteDeadline(time.Time{})
+	}
+}
+
+// replaces i/o timeouts caused by the context ending with the context error
+func contextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var ne net.Error
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) && errors.As(err, &ne) && ne.Timeout() {
+		return context.DeadlineExceeded
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package mcr
+
+import (
+	"fmt"
+)
+
+// maximum responses to earlier requests discarded while waiting for the expected response
+const maxStalePackets = 64
+
+// option to verify every response answers the request that was sent. Responses to earlier requests are
+// discarded until the expected one arrives, ErrRequestIDMismatch is returned when the stream cannot be
+// resynchronized or a fragment of a split response carries another request id
+func WithStrictCorrelation() Option {
+	return func(cn *Client) {
+		cn.strict = true
+	}
+}
+
+// discards responses until the response to the request id arrives. Servers reply to rejected authentication
+// with the failure id so it is accepted for auth requests
+func (c *Client) correlate(res *Response, id int32, auth bool) (*Response, error) {
+	var err error
+	for discarded := 0; ; discarded++ {
+		if res.RequestID == id || (auth && res.RequestID == FailurePacket) {
+			return res, nil
+		}
+		if discarded == maxStalePackets {
+			return nil, fmt.Errorf("%w: sent %d, still receiving %d after discarding %d stale packets", ErrRequestIDMismatch, id, res.RequestID, discarded)
+		}
+
+		res, err = c.recv()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package mcr
+
+import (
+	"encoding/json"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	traceEntries = 32  //recent requests kept for debug bundles
+	errorEntries = 16  //recent failed requests kept for debug bundles
+	traceCommand = 256 //commands are truncated to this many bytes in traces
+)
+
+// summary of a request kept for debug bundles, bodies are not kept, passwords are redacted, and only the
+// command verb is kept unless WithFullTrace is used
+type TraceEntry struct {
+	At            time.Time     `json:"at"`
+	RequestID     int32         `json:"request_id"`
+	Type          string        `json:"type"`
+	Command       string        `json:"command"`
+	ResponseBytes int           `json:"response_bytes"`
+	Fragments     int           `json:"fragments"`
+	Latency       time.Duration `json:"latency"`
+	Err           string        `json:"error,omitempty"`
+}
+
+// diagnostics collected by Client.DebugBundle
+type DebugBundle struct {
+	Generated time.Time    `json:"generated"`
+	GoVersion string       `json:"go_version"`
+	Platform  string       `json:"platform"`
+	State     string       `json:"state"`
+	Config    Config       `json:"config"`
+	Quirks    string       `json:"quirks"`
+	Stats     Stats        `json:"stats"`
+	Trace     []TraceEntry `json:"trace"`  //recent requests, oldest first
+	Errors    []TraceEntry `json:"errors"` //recent failed requests, oldest first
+}
+
+// returns a JSON report of the client configuration, active quirks, statistics, recent requests, and recent
+// errors to attach to bug reports. Passwords are never recorded and response bodies are not included
+func (c *Client) DebugBundle() ([]byte, error) {
+	trace, errs := c.trace.snapshot()
+	return json.MarshalIndent(DebugBundle{
+		Generated: time.Now(),
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		State:     c.State().String(),
+		Config:    c.Config(),
+		Quirks:    c.activeQuirks().String(),
+		Stats:     c.Stats(),
+		Trace:     trace,
+		Errors:    errs,
+	}, "", "  ")
+}
+
+// option to keep the full command text in debug bundle traces instead of only the verb, command arguments
+// may contain player names, messages, or secrets passed to plugins so only enable it when reproducing a bug
+func WithFullTrace() Option {
+	return func(cn *Client) {
+		cn.trace.full = true
+	}
+}
+
+// rings of recent and failed requests, safe for concurrent use so bundles can be built while commands run
+type traceLog struct {
+	mu     sync.Mutex
+	full   bool //keeps the full command text instead of only the verb
+	recent []TraceEntry
+	errors []TraceEntry
+}
+
+// records a request, keeping only the command verb unless full commands are enabled and truncating the
+// command
+func (t *traceLog) record(entry TraceEntry) {
+	if !t.full && entry.Type == SourcePacketTypes.Request(CommandPacket) {
+		entry.Command = commandVerb(entry.Command)
+	}
+	if len(entry.Command) > traceCommand {
+		entry.Command = entry.Command[:traceCommand] + "..."
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recent = appendRing(t.recent, entry, traceEntries)
+	if entry.Err != "" {
+		t.errors = appendRing(t.errors, entry, errorEntries)
+	}
+}
+
+// returns copies of the recent and failed requests
+func (t *traceLog) snapshot() ([]TraceEntry, []TraceEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceEntry{}, t.recent...), append([]TraceEntry{}, t.errors...)
+}
+
+// appends the entry, dropping the oldest entry once the ring holds size entries
+func appendRing(ring []TraceEntry, entry TraceEntry, size int) []TraceEntry {
+	if len(ring) == size {
+		copy(ring, ring[1:])
+		ring = ring[:size-1]
+	}
+	return append(ring, entry)
+}
+
+// returns the error message or an empty string
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
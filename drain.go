@@ -0,0 +1,49 @@
+package mcr
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// discards stale packets left on the connection after a timeout or failed request, returning the number of
+// bytes discarded. Reading stops once the server is quiet for the fragment timeout, an error is returned if
+// data is still arriving after the drain timeout or the drain byte limit is exceeded. Drain is called
+// automatically before the next command after a failed request
+func (c *Client) Drain() (int, error) {
+	if c.connection == nil {
+		return 0, ErrNotConnected
+	}
+	defer c.connection.SetReadDeadline(time.Time{})
+
+	limit := time.Now().Add(c.drainTimeout)
+	buf := make([]byte, 4096)
+	total := 0
+	for {
+		if time.Now().After(limit) {
+			return total, ErrDrainTimeout
+		}
+
+		err := c.connection.SetReadDeadline(time.Now().Add(c.fragmentTimeout))
+		if err != nil {
+			return total, err
+		}
+		if c.ctx != nil && c.ctx.Err() != nil { //drain runs inside CommandContext, see bind
+			return total, c.ctx.Err()
+		}
+
+		n, err := c.connection.Read(buf)
+		total += n
+		if total > c.drainLimit {
+			return total, ErrDrainLimit
+		}
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() { //server is quiet, stream is in sync
+				c.stale = false
+				return total, nil
+			}
+			return total, err
+		}
+	}
+}
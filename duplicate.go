@@ -0,0 +1,56 @@
+package mcr
+
+import (
+	"strings"
+	"time"
+)
+
+// guards against identical state-changing commands sent within the window, such as a ban issued twice by a
+// double-clicked button. Query commands are never treated as duplicates
+type DuplicatePolicy struct {
+	Window  time.Duration                             //identical commands sent within this duration are duplicates
+	Confirm func(cmd string, previous time.Time) bool `json:"-"` //returns true to send the duplicate, a nil callback suppresses all duplicates
+}
+
+// option to suppress or confirm duplicate state-changing commands, suppressed commands return ErrDuplicateCommand
+func WithDuplicateSuppression(policy DuplicatePolicy) Option {
+	return func(cn *Client) {
+		cn.duplicates = &duplicateGuard{policy: policy, sent: make(map[string]time.Time)}
+	}
+}
+
+// tracks when state-changing commands were last sent
+type duplicateGuard struct {
+	policy DuplicatePolicy
+	sent   map[string]time.Time //normalized command to the time it was last sent
+}
+
+// returns true if the command can be sent, commands are compared ignoring case, leading slashes, and repeated
+// whitespace. Commands only count as sent once record is called so failed attempts can be retried
+func (g *duplicateGuard) check(cmd string, now time.Time) bool {
+	if g == nil || isQueryCommand(cmd) {
+		return true
+	}
+
+	for k, t := range g.sent {
+		if now.Sub(t) >= g.policy.Window {
+			delete(g.sent, k)
+		}
+	}
+
+	previous, ok := g.sent[duplicateKey(cmd)]
+	return !ok || (g.policy.Confirm != nil && g.policy.Confirm(cmd, previous))
+}
+
+// records a command the server received
+func (g *duplicateGuard) record(cmd string, now time.Time) {
+	if g == nil || isQueryCommand(cmd) {
+		return
+	}
+	g.sent[duplicateKey(cmd)] = now
+}
+
+// normalizes a command for comparison
+func duplicateKey(cmd string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.TrimLeft(cmd, "/ \t"))), " ")
+}
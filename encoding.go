@@ -0,0 +1,86 @@
+package mcr
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// character encoding used to decode response bodies
+type Encoding int
+
+const (
+	EncodingAuto    Encoding = iota //detect the encoding from the response bytes
+	EncodingUTF8                    //bodies are used as-is
+	EncodingLatin1                  //each byte is mapped to the matching unicode code point
+	EncodingEscaped                 //bodies are UTF-8 with \uXXXX escape sequences for non-ascii characters
+)
+
+// decodes a response body using the supplied encoding. EncodingAuto treats invalid UTF-8 as Latin-1 and
+// expands any \uXXXX escape sequences found in valid UTF-8
+func decodeBody(payload []byte, enc Encoding) string {
+	switch enc {
+	case EncodingUTF8:
+		return string(payload)
+	case EncodingLatin1:
+		return decodeLatin1(payload)
+	case EncodingEscaped:
+		return unescapeUnicode(string(payload))
+	}
+
+	if !utf8.Valid(payload) {
+		return decodeLatin1(payload)
+	}
+	return unescapeUnicode(string(payload))
+}
+
+// converts Latin-1 bytes to a UTF-8 string
+func decodeLatin1(payload []byte) string {
+	runes := make([]rune, len(payload))
+	for i, b := range payload {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// expands \uXXXX escape sequences including surrogate pairs, malformed sequences are left untouched
+func unescapeUnicode(s string) string {
+	if !strings.Contains(s, `\u`) {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		r, ok := parseEscape(s, i)
+		if !ok {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i += 5
+		if utf16.IsSurrogate(r) {
+			low, ok := parseEscape(s, i+1)
+			if ok {
+				if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+					sb.WriteRune(pair)
+					i += 6
+					continue
+				}
+			}
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// parses the \uXXXX escape sequence starting at index i
+func parseEscape(s string, i int) (rune, bool) {
+	if i+6 > len(s) || s[i] != '\\' || s[i+1] != 'u' {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s[i+2:i+6], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(v), true
+}
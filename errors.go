@@ -0,0 +1,72 @@
+package mcr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errors returned by the client, compare with errors.Is since most are wrapped with details
+var (
+	ErrAuthFailed               = errors.New("authentication failed")                                    //the server rejected the password
+	ErrMissingPassword          = errors.New("no password supplied")                                     //see WithRequirePassword and EmptyPassword
+	ErrInvalidPassword          = errors.New("invalid password")                                         //the password cannot be sent
+	ErrNotConnected             = errors.New("the Connect method must be called first")                  //the client has no connection
+	ErrAlreadyConnected         = errors.New("the client is already connected")                          //the operation requires a disconnected client
+	ErrNoEndpoints              = errors.New("no failover endpoints configured")                         //see NewFailoverClient
+	ErrConnectionClosed         = errors.New("connection closed")                                        //the connection broke during a request
+	ErrTruncatedResponse        = errors.New("response truncated by the server")                         //see TruncatedResponseError
+	ErrResponseTooLarge         = errors.New("response exceeds the maximum size")                        //the server announced an oversized response
+	ErrInvalidPacketSize        = errors.New("invalid packet size")                                      //the Size header is too small to hold a packet
+	ErrRequestIDMismatch        = errors.New("response request id does not match the request")           //the response belongs to another request
+	ErrInvalidPacketType        = errors.New("invalid packet type")                                      //see PacketTypeError
+	ErrReadOnly                 = errors.New("only query commands can be run by a read-only client")     //see WithReadOnly
+	ErrNotConfirmed             = errors.New("the command was not confirmed")                            //see WithConfirmation
+	ErrOutsideMaintenanceWindow = errors.New("the command is outside the maintenance windows")           //see WithMaintenanceWindows
+	ErrDuplicateCommand         = errors.New("the command duplicates one sent moments ago")              //see WithDuplicateSuppression
+	ErrUnsafeCommand            = errors.New("the command contains control characters or section signs") //see WithSanitization
+	ErrUnsupportedAction        = errors.New("the moderation action is not supported by the server")     //see ModerationSyntax
+	ErrNonConformant            = errors.New("response does not conform to the specification")           //see ConformanceError
+	ErrDrainTimeout             = errors.New("stale data is still arriving after the drain timeout")     //see WithDrainLimits
+	ErrDrainLimit               = errors.New("stale data exceeded the drain byte limit")                 //see WithDrainLimits
+	ErrInvalidArgument          = errors.New("invalid argument")                                         //a command argument or schedule failed validation
+	ErrUnrecognizedResponse     = errors.New("the response is not in the expected format")               //a queried command returned unexpected text
+	ErrNoPlayersOnline          = errors.New("no players are online")                                    //see ResolveContext
+)
+
+// describes a response body cut short by the server closing the connection, matches ErrTruncatedResponse
+// with errors.Is
+type TruncatedResponseError struct {
+	Partial  []byte //body bytes read before the connection closed
+	Expected int    //body size announced by the Size header
+}
+
+func (e *TruncatedResponseError) Error() string {
+	return fmt.Sprintf("%s: read %d of %d body bytes", ErrTruncatedResponse, len(e.Partial), e.Expected)
+}
+
+func (e *TruncatedResponseError) Is(target error) bool {
+	return target == ErrTruncatedResponse
+}
+
+// describes a packet type the protocol does not allow in responses, matches ErrInvalidPacketType with errors.Is
+type PacketTypeError struct {
+	Protocol string     //protocol of the packet type table used for validation
+	Type     PacketType //type received from the server
+	Expected string     //allowed response types
+}
+
+func (e *PacketTypeError) Error() string {
+	return fmt.Sprintf("invalid %s response packet type %d, expected one of %s", e.Protocol, int32(e.Type), e.Expected)
+}
+
+func (e *PacketTypeError) Is(target error) bool {
+	return target == ErrInvalidPacketType
+}
+
+// wraps errors showing the connection can no longer be used with ErrConnectionClosed
+func closedError(err error) error {
+	if err == nil || errors.Is(err, ErrConnectionClosed) || !isBroken(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrConnectionClosed, err)
+}
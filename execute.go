@@ -0,0 +1,108 @@
+package mcr
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jake-young-dev/mcr/selector"
+)
+
+var (
+	playerPattern    = regexp.MustCompile(`^[A-Za-z0-9_]{1,16}$`)
+	uuidPattern      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	namespacePattern = regexp.MustCompile(`^([a-z0-9_.-]+:)?[a-z0-9_./-]+$`)
+)
+
+// builds a command wrapped in execute clauses, the first invalid clause is reported when the command runs
+type Execution struct {
+	client  *Client
+	clauses []string
+	err     error
+}
+
+// runs commands as the target entity
+func (c *Client) As(target string) *Execution {
+	return (&Execution{client: c}).As(target)
+}
+
+// runs commands in the dimension
+func (c *Client) In(dimension string) *Execution {
+	return (&Execution{client: c}).In(dimension)
+}
+
+// runs commands at the position and rotation of the target entity
+func (c *Client) At(target string) *Execution {
+	return (&Execution{client: c}).At(target)
+}
+
+// runs commands as the target entity
+func (e *Execution) As(target string) *Execution {
+	return e.target("as", target)
+}
+
+// runs commands at the position and rotation of the target entity
+func (e *Execution) At(target string) *Execution {
+	return e.target("at", target)
+}
+
+// runs commands in the dimension, for example minecraft:the_nether
+func (e *Execution) In(dimension string) *Execution {
+	if e.err == nil && !namespacePattern.MatchString(dimension) {
+		e.err = fmt.Errorf("%w: invalid dimension %q", ErrInvalidArgument, dimension)
+	}
+	e.clauses = append(e.clauses, "in "+dimension)
+	return e
+}
+
+// runs commands at the coordinates
+func (e *Execution) Positioned(x, y, z float64) *Execution {
+	e.clauses = append(e.clauses, "positioned "+formatCoord(x)+" "+formatCoord(y)+" "+formatCoord(z))
+	return e
+}
+
+// adds a clause targeting an entity after validating the target
+func (e *Execution) target(clause, target string) *Execution {
+	if e.err == nil && !validTarget(target) {
+		e.err = fmt.Errorf("%w: invalid %s target %q", ErrInvalidArgument, clause, target)
+	}
+	e.clauses = append(e.clauses, clause+" "+target)
+	return e
+}
+
+// returns the full execute command for cmd
+func (e *Execution) Build(cmd string) (string, error) {
+	if e.err != nil {
+		return "", e.err
+	}
+	return "execute " + strings.Join(e.clauses, " ") + " run " + strings.TrimPrefix(cmd, "/"), nil
+}
+
+// sends cmd wrapped in the execute clauses and returns the server response
+func (e *Execution) Command(cmd string) (string, error) {
+	return e.CommandContext(context.Background(), cmd)
+}
+
+// sends cmd wrapped in the execute clauses and returns the server response, see Client.CommandContext
+func (e *Execution) CommandContext(ctx context.Context, cmd string) (string, error) {
+	full, err := e.Build(cmd)
+	if err != nil {
+		return "", err
+	}
+	return e.client.CommandContext(ctx, full)
+}
+
+// returns true if the target is a player name, uuid, or valid target selector
+func validTarget(target string) bool {
+	if strings.HasPrefix(target, "@") {
+		return selector.Validate(target) == nil
+	}
+	return playerPattern.MatchString(target) || uuidPattern.MatchString(target)
+}
+
+// formats a coordinate without trailing zeros
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,158 @@
+package mcr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// default time spent on a secondary endpoint before the primary is probed
+const DefaultFailbackAfter = time.Minute
+
+// address of an RCon server
+type Endpoint struct {
+	Address string
+	Port    int
+}
+
+// hysteresis controls for a FailoverClient
+type FailoverPolicy struct {
+	FailureThreshold int           //consecutive failed commands before switching endpoints, values below one switch on the first failure
+	FailbackAfter    time.Duration //time spent on a secondary endpoint before the primary is probed, also the interval between probes. Zero uses DefaultFailbackAfter and negative values never fail back
+}
+
+// client spread across an ordered list of endpoints such as a proxy and the backend behind it. Commands use
+// the first endpoint that accepts the connection, persistent failures switch to the next endpoint and the
+// primary endpoint is probed periodically so the client fails back once it recovers. Like Client it is not
+// safe for concurrent use
+type FailoverClient struct {
+	endpoints []Endpoint
+	policy    FailoverPolicy
+	opts      []Option
+	password  string
+
+	client   *Client   //client of the active endpoint
+	active   int       //index of the active endpoint
+	failures int       //consecutive failed commands on the active endpoint
+	probed   time.Time //time of the last switch or primary probe
+}
+
+// creates a failover client, the options are applied to the client of every endpoint
+func NewFailoverClient(endpoints []Endpoint, policy FailoverPolicy, opts ...Option) *FailoverClient {
+	if policy.FailbackAfter == 0 {
+		policy.FailbackAfter = DefaultFailbackAfter
+	}
+	return &FailoverClient{
+		endpoints: endpoints,
+		policy:    policy,
+		opts:      opts,
+	}
+}
+
+// connects to the first endpoint that accepts the password
+func (f *FailoverClient) Connect(password string) error {
+	return f.ConnectContext(context.Background(), password)
+}
+
+// connects to the first endpoint that accepts the password, the context bounds every attempt
+func (f *FailoverClient) ConnectContext(ctx context.Context, password string) error {
+	f.password = password
+	return f.switchFrom(ctx, -1)
+}
+
+// sends a command to the active endpoint
+func (f *FailoverClient) Command(cmd string) (string, error) {
+	return f.CommandContext(context.Background(), cmd)
+}
+
+// sends a command to the active endpoint, failing back to the primary when it has recovered. Once failures
+// reach the threshold the client switches to the next endpoint and retries the command there once
+func (f *FailoverClient) CommandContext(ctx context.Context, cmd string) (string, error) {
+	if f.client == nil {
+		return "", ErrNotConnected
+	}
+
+	if f.active > 0 && f.policy.FailbackAfter > 0 && time.Since(f.probed) >= f.policy.FailbackAfter {
+		f.probed = time.Now()
+		primary := f.dial(0)
+		if primary.ConnectContext(ctx, f.password) == nil {
+			f.client.Close()
+			f.client, f.active, f.failures = primary, 0, 0
+		} else {
+			primary.Close()
+		}
+	}
+
+	res, err := f.client.CommandContext(ctx, cmd)
+	if err == nil || ctx.Err() != nil || !endpointFailure(err) { //cancelled commands say nothing about the endpoint
+		f.failures = 0
+		return res, err
+	}
+
+	f.failures++
+	if f.failures < f.policy.FailureThreshold {
+		return "", err
+	}
+	serr := f.switchFrom(ctx, f.active)
+	if serr != nil {
+		return "", errors.Join(err, serr)
+	}
+	return f.client.CommandContext(ctx, cmd)
+}
+
+// closes the connection to the active endpoint
+func (f *FailoverClient) Close() error {
+	if f.client == nil {
+		return nil
+	}
+	err := f.client.Close()
+	f.client = nil
+	return err
+}
+
+// returns the endpoint commands are sent to
+func (f *FailoverClient) Active() Endpoint {
+	return f.endpoints[f.active]
+}
+
+// connects to the endpoints following the current one in order, wrapping around to the primary, and makes
+// the first that accepts the password active. A negative index starts with the primary
+func (f *FailoverClient) switchFrom(ctx context.Context, current int) error {
+	if len(f.endpoints) == 0 {
+		return ErrNoEndpoints
+	}
+
+	var errs []error
+	for i := 1; i <= len(f.endpoints); i++ {
+		next := (current + i) % len(f.endpoints)
+		if current < 0 {
+			next = i - 1
+		}
+		c := f.dial(next)
+		err := c.ConnectContext(ctx, f.password)
+		if err == nil {
+			f.Close()
+			f.client, f.active, f.failures, f.probed = c, next, 0, time.Now()
+			return nil
+		}
+		c.Close()
+		errs = append(errs, err)
+		if ctx.Err() != nil { //the remaining endpoints would fail the same way
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// creates a client for the endpoint
+func (f *FailoverClient) dial(i int) *Client {
+	opts := append(append([]Option{}, f.opts...), WithPort(f.endpoints[i].Port))
+	return NewClient(f.endpoints[i].Address, opts...)
+}
+
+// returns true if the error suggests the endpoint is unhealthy rather than the command being rejected
+func endpointFailure(err error) bool {
+	var ne net.Error
+	return isBroken(err) || errors.As(err, &ne)
+}
@@ -0,0 +1,41 @@
+package mcr
+
+import (
+	"encoding/json"
+)
+
+// serializable client configuration and request tracking used for handoffs between processes, the live
+// connection is never included
+type clientState struct {
+	Config
+	RequestID int32 `json:"request_id"`
+}
+
+// serializes the client settings and request id so another process can take over with Import. The
+// connection itself cannot be handed off, the importing process must call Connect
+func (c *Client) Export() ([]byte, error) {
+	return json.Marshal(clientState{
+		Config:    c.Config(),
+		RequestID: c.requestID,
+	})
+}
+
+// restores settings created by Export, an error is returned if the client is already connected
+func (c *Client) Import(data []byte) error {
+	if c.connection != nil {
+		return ErrAlreadyConnected
+	}
+
+	var state clientState
+	err := json.Unmarshal(data, &state)
+	if err != nil {
+		return err
+	}
+
+	err = c.setConfig(state.Config)
+	if err != nil {
+		return err
+	}
+	c.requestID = state.RequestID
+	return nil
+}
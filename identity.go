@@ -0,0 +1,76 @@
+package mcr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// default format of the identity prefix
+const DefaultIdentityFormat = "[%s] "
+
+// context key holding the initiating identity
+type identityKey struct{}
+
+// returns a context carrying the identity that initiated the commands sent with it, such as "admin:alice"
+func WithIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// returns the identity carried by the context
+func IdentityFrom(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(identityKey{}).(string)
+	return identity, ok && identity != ""
+}
+
+// option to prefix the output of say, broadcast, and tellraw commands with the identity from the command
+// context so players can see who sent a message. The format receives the identity as its only argument, an
+// empty format uses DefaultIdentityFormat. Commands without an identity in their context are sent unchanged
+func WithIdentityPrefix(format string) Option {
+	return func(cn *Client) {
+		if format == "" {
+			format = DefaultIdentityFormat
+		}
+		cn.identityFormat = format
+	}
+}
+
+// adds the identity prefix to broadcast commands
+func prefixIdentity(cmd, format, identity string) string {
+	prefix := fmt.Sprintf(format, identity)
+	slash := cmd[:len(cmd)-len(strings.TrimLeft(cmd, "/ \t"))]
+	verb, args, _ := strings.Cut(cmd[len(slash):], " ")
+
+	switch strings.ToLower(verb) {
+	case "say", "broadcast":
+		return slash + verb + " " + prefix + args
+	case "tellraw":
+		target, component := splitTarget(args)
+		if component == "" {
+			return cmd
+		}
+		p, _ := json.Marshal(prefix)
+		return fmt.Sprintf("%s%s %s [%s,%s]", slash, verb, target, p, component)
+	}
+	return cmd
+}
+
+// splits the first argument from the rest, selectors with bracketed arguments may contain spaces
+func splitTarget(args string) (string, string) {
+	args = strings.TrimLeft(args, " ")
+	depth := 0
+	for i, r := range args {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ' ':
+			if depth <= 0 {
+				return args[:i], strings.TrimSpace(args[i+1:])
+			}
+		}
+	}
+	return args, ""
+}
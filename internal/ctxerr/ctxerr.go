@@ -0,0 +1,32 @@
+// Package ctxerr maps i/o errors caused by a context ending to the context error, shared by the clients that
+// bind socket deadlines to their context
+package ctxerr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// returns true once the context is done or its deadline has passed. A socket deadline set to the context
+// deadline can fire before the context reports that it ended
+func Expired(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	return ctx.Err() != nil || (ok && !time.Now().Before(deadline))
+}
+
+// replaces i/o timeouts caused by the context ending with the context error
+func Wrap(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() && Expired(ctx) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
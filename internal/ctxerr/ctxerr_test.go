@@ -0,0 +1,37 @@
+package ctxerr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// testing socket timeouts at the context deadline report the context error before the context ends
+func TestWrap(t *testing.T) {
+	ctx := context.Context(lateContext{Context: context.Background(), deadline: time.Now().Add(-time.Millisecond)})
+	if err := Wrap(ctx, os.ErrDeadlineExceeded); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if err := Wrap(ctx, os.ErrDeadlineExceeded); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("timeouts before the deadline must be kept, got %v", err)
+	}
+	if Wrap(ctx, nil) != nil {
+		t.Fatal("expected nil")
+	}
+}
+
+// context whose deadline has passed but has not reported ending yet, as happens for a moment after the
+// deadline timer fires
+type lateContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c lateContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
@@ -0,0 +1,53 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testing the accept key against the example from RFC 6455
+func TestAcceptKey(t *testing.T) {
+	if acceptKey("dGhlIHNhbXBsZSBub25jZQ==") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatal("unexpected accept key")
+	}
+}
+
+// testing a client and server exchange bytes including payloads needing extended lengths
+func TestEcho(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(conn, conn)
+	}))
+	defer srv.Close()
+
+	conn, err := Dial(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, size := range []int{1, 125, 126, 70000} {
+		msg := bytes.Repeat([]byte{'x'}, size)
+		_, err = conn.Write(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, size)
+		_, err = io.ReadFull(conn, got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Fatalf("echoed %d byte message did not match", size)
+		}
+	}
+}
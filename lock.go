@@ -0,0 +1,60 @@
+package mcr
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// default interval between attempts to take a busy lock
+const DefaultLockInterval = 100 * time.Millisecond
+
+// mutual exclusion shared by every process sending commands to a server, implementations can wrap file
+// locks, databases, or distributed lock services. Lock blocks until the lock is held or the context ends and
+// returns a func releasing the lock
+type Locker interface {
+	Lock(ctx context.Context) (unlock func() error, err error)
+}
+
+// cross-process lock backed by a file, processes on the same host using the same path exclude each other.
+// Platforms with flock release the lock when the holding process exits, elsewhere the file is created
+// exclusively and removed on unlock so a crashed holder leaves a file that must be removed by hand
+type FileLocker struct {
+	Path     string        //lock file, created if it does not exist
+	Interval time.Duration //time between attempts while the lock is busy, defaults to DefaultLockInterval
+}
+
+func (l *FileLocker) Lock(ctx context.Context) (func() error, error) {
+	interval := l.Interval
+	if interval <= 0 {
+		interval = DefaultLockInterval
+	}
+
+	for {
+		unlock, busy, err := l.tryLock()
+		if err != nil {
+			return nil, err
+		}
+		if !busy {
+			return unlock, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("lock %s: %w", l.Path, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// holds the lock while fn runs so scripts from other processes cannot interleave their commands with it, for
+// example two backup jobs toggling save-off at the same time
+func (c *Client) Exclusive(ctx context.Context, locker Locker, fn func(c *Client) error) error {
+	unlock, err := locker.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return fn(c)
+}
@@ -0,0 +1,24 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package mcr
+
+import (
+	"errors"
+	"os"
+)
+
+// creates the lock file exclusively, busy is true when the file already exists
+func (l *FileLocker) tryLock() (unlock func() error, busy bool, err error) {
+	f, err := os.OpenFile(l.Path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	f.Close()
+
+	return func() error {
+		return os.Remove(l.Path)
+	}, false, nil
+}
@@ -0,0 +1,207 @@
+// Package logtail follows a Minecraft server log file and parses chat, join, leave, and death events. RCon
+// cannot push events to clients so tailing the log is the only way to observe them as they happen
+package logtail
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	//default values
+	DefaultPollInterval = time.Second
+)
+
+// type of log event
+type Kind int
+
+const (
+	KindJoin Kind = iota + 1
+	KindLeave
+	KindChat
+	KindDeath
+)
+
+// event parsed from a server log line
+type Event struct {
+	Time    time.Time //time the line was logged, the log only records the clock time so today's date is used
+	Kind    Kind
+	Player  string //player the event applies to
+	Message string //chat message or full death message
+	Line    string //raw log line
+}
+
+var (
+	//matches "[12:34:56] [Server thread/INFO]: msg" and "[12:34:56 INFO]: msg"
+	linePattern  = regexp.MustCompile(`^\[(\d{2}:\d{2}:\d{2})[^\]]*\](?: \[[^\]]*\])?: (.*)$`)
+	joinPattern  = regexp.MustCompile(`^(\w{1,16}) joined the game$`)
+	leavePattern = regexp.MustCompile(`^(\w{1,16}) left the game$`)
+	chatPattern  = regexp.MustCompile(`^(?:\[Not Secure\] )?<(\w{1,16})> (.*)$`)
+	deathPattern = regexp.MustCompile(`^(\w{1,16}) (was |drowned|died|fell |hit the ground|burned|tried to swim|starved|suffocated|experienced kinetic|went up in flames|walked into|froze to death|withered away|blew up|discovered the floor|left the confines)`)
+)
+
+// parses a single log line, false is returned for lines that are not chat, join, leave, or death events
+func Parse(line string) (Event, bool) {
+	m := linePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if m == nil {
+		return Event{}, false
+	}
+
+	ev := Event{Line: line}
+	clock, err := time.ParseInLocation("15:04:05", m[1], time.Local)
+	if err == nil {
+		now := time.Now()
+		ev.Time = time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, time.Local)
+	}
+
+	msg := m[2]
+	if sub := chatPattern.FindStringSubmatch(msg); sub != nil {
+		ev.Kind, ev.Player, ev.Message = KindChat, sub[1], sub[2]
+	} else if sub := joinPattern.FindStringSubmatch(msg); sub != nil {
+		ev.Kind, ev.Player = KindJoin, sub[1]
+	} else if sub := leavePattern.FindStringSubmatch(msg); sub != nil {
+		ev.Kind, ev.Player = KindLeave, sub[1]
+	} else if sub := deathPattern.FindStringSubmatch(msg); sub != nil {
+		ev.Kind, ev.Player, ev.Message = KindDeath, sub[1], msg
+	} else {
+		return Event{}, false
+	}
+	return ev, true
+}
+
+// follows a log file across rotations, the zero value is not usable and NewTailer must be used
+type Tailer struct {
+	path      string
+	interval  time.Duration
+	fromStart bool //read existing lines instead of starting at the end of the file
+}
+
+// tailer option func skeleton
+type Option func(t *Tailer)
+
+// option to allow for custom intervals between checks for new lines
+func WithPollInterval(interval time.Duration) Option {
+	return func(t *Tailer) {
+		t.interval = interval
+	}
+}
+
+// option to emit events for lines already in the file when tailing starts
+func WithFromStart() Option {
+	return func(t *Tailer) {
+		t.fromStart = true
+	}
+}
+
+// creates a tailer for the log file at path, usually logs/latest.log in the server directory
+func NewTailer(path string, opts ...Option) *Tailer {
+	t := &Tailer{
+		path:     path,
+		interval: DefaultPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// sends parsed events to the channel until the context is cancelled. When the file is rotated the new file
+// is read from the beginning
+func (t *Tailer) Run(ctx context.Context, events chan<- Event) error {
+	var (
+		offset  int64
+		current os.FileInfo
+		partial string
+	)
+
+	if !t.fromStart {
+		fi, err := os.Stat(t.path)
+		if err == nil {
+			offset, current = fi.Size(), fi
+		}
+	}
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		fi, err := os.Stat(t.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err == nil {
+			if current == nil || !os.SameFile(current, fi) || fi.Size() < offset { //rotated or truncated
+				offset, partial = 0, ""
+			}
+			current = fi
+
+			if fi.Size() > offset {
+				lines, n, err := readLines(t.path, offset)
+				if err != nil {
+					return err
+				}
+				offset += n
+				for _, line := range lines {
+					if !strings.HasSuffix(line, "\n") { //line is still being written
+						partial += line
+						continue
+					}
+					line, partial = partial+line, ""
+					if ev, ok := Parse(line); ok {
+						select {
+						case events <- ev:
+						case <-ctx.Done():
+							return ctx.Err()
+						}
+					}
+				}
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// reads the lines after offset returning them with their line endings and the number of bytes read
+func readLines(path string, offset int64) ([]string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		lines []string
+		n     int64
+	)
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			lines = append(lines, line)
+			n += int64(len(line))
+		}
+		if err == io.EOF {
+			return lines, n, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+}
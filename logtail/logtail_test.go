@@ -0,0 +1,66 @@
+package logtail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testing log line parsing for each event kind
+func TestParse(t *testing.T) {
+	cases := []struct {
+		line   string
+		kind   Kind
+		player string
+	}{
+		{"[12:34:56] [Server thread/INFO]: Steve joined the game", KindJoin, "Steve"},
+		{"[12:34:56 INFO]: Steve left the game", KindLeave, "Steve"},
+		{"[12:34:56] [Server thread/INFO]: <Alex> hello there", KindChat, "Alex"},
+		{"[12:34:56] [Server thread/INFO]: [Not Secure] <Alex> hi", KindChat, "Alex"},
+		{"[12:34:56] [Server thread/INFO]: Steve was slain by Zombie", KindDeath, "Steve"},
+		{"[12:34:56] [Server thread/INFO]: Alex fell from a high place", KindDeath, "Alex"},
+	}
+	for _, c := range cases {
+		ev, ok := Parse(c.line)
+		if !ok || ev.Kind != c.kind || ev.Player != c.player {
+			t.Fatalf("unexpected event %+v for line %q", ev, c.line)
+		}
+	}
+
+	if _, ok := Parse("[12:34:56] [Server thread/INFO]: Starting minecraft server version 1.21"); ok {
+		t.Fatal("server message was parsed as an event")
+	}
+}
+
+// testing new lines appended to the log are emitted as events
+func TestTailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "latest.log")
+	err := os.WriteFile(path, []byte("[12:00:00] [Server thread/INFO]: Old joined the game\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan Event)
+	go NewTailer(path, WithPollInterval(time.Millisecond*10)).Run(ctx, events)
+	time.Sleep(time.Millisecond * 30)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("[12:00:01] [Server thread/INFO]: New joined the game\n")
+	f.Close()
+
+	select {
+	case ev := <-events:
+		if ev.Player != "New" {
+			t.Fatalf("expected only new lines to be emitted, got %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event was emitted for the appended line")
+	}
+}
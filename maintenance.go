@@ -0,0 +1,240 @@
+package mcr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recurring period during which state-changing commands are allowed. Windows open at the times matched by a
+// cron schedule and stay open for the duration
+type MaintenanceWindow struct {
+	spec     string
+	duration time.Duration
+	location *time.Location
+
+	minute [60]bool
+	hour   [24]bool
+	dom    [32]bool
+	month  [13]bool
+	dow    [7]bool
+	anyDom bool //day of month field is *
+	anyDow bool //day of week field is *
+}
+
+// restricts state-changing commands to maintenance windows, query commands are always allowed
+type MaintenancePolicy struct {
+	Windows []*MaintenanceWindow //commands are allowed while any window is open
+	Queue   bool                 //blocks commands until the next window opens instead of rejecting them
+}
+
+// parses a maintenance window from a five field cron schedule (minute hour day-of-month month day-of-week)
+// evaluated in the location, a nil location uses local time. Fields accept *, numbers, ranges (1-5), lists
+// (1,3,5), and steps (*/15 or 0-30/10), day of week 0 and 7 are Sunday. As with cron, a window whose day of
+// month and day of week are both restricted opens on days matching either field
+//
+//	ParseMaintenanceWindow("0 3 * * 0", 2*time.Hour, time.UTC) //Sundays from 03:00 to 05:00 UTC
+func ParseMaintenanceWindow(spec string, duration time.Duration, location *time.Location) (*MaintenanceWindow, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: maintenance window %q must have 5 fields", ErrInvalidArgument, spec)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("%w: maintenance window %q must have a positive duration", ErrInvalidArgument, spec)
+	}
+	if location == nil {
+		location = time.Local
+	}
+
+	w := &MaintenanceWindow{spec: spec, duration: duration, location: location}
+	dow := make([]bool, 8)
+	for i, f := range []struct {
+		set      []bool
+		min, max int
+	}{
+		{w.minute[:], 0, 59},
+		{w.hour[:], 0, 23},
+		{w.dom[:], 1, 31},
+		{w.month[:], 1, 12},
+		{dow, 0, 7},
+	} {
+		err := parseCronField(fields[i], f.set, f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: maintenance window %q: %w", ErrInvalidArgument, spec, err)
+		}
+	}
+	copy(w.dow[:], dow)
+	w.dow[0] = w.dow[0] || dow[7]
+	w.anyDom = fields[2] == "*"
+	w.anyDow = fields[4] == "*"
+	return w, nil
+}
+
+// marks the values matched by a cron field
+func parseCronField(field string, set []bool, min, max int) error {
+	for _, part := range strings.Split(field, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		lo, hi := min, max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			lo, err = strconv.Atoi(a)
+			if err != nil {
+				return fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				hi, err = strconv.Atoi(b)
+				if err != nil {
+					return fmt.Errorf("invalid range %q", part)
+				}
+			} else if hasStep {
+				hi = max //a step from a single value runs to the end of the field, as in cron
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+
+		n := 1
+		if hasStep {
+			var err error
+			n, err = strconv.Atoi(step)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid step %q", part)
+			}
+		}
+		for v := lo; v <= hi; v += n {
+			set[v] = true
+		}
+	}
+	return nil
+}
+
+// returns the cron schedule the window was parsed from
+func (w *MaintenanceWindow) String() string {
+	return w.spec
+}
+
+// serialized form of a maintenance window, the schedule is parsed again when it is decoded
+type maintenanceWindowJSON struct {
+	Spec     string        `json:"spec"`
+	Duration time.Duration `json:"duration"`
+	Location string        `json:"location"`
+}
+
+func (w *MaintenanceWindow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(maintenanceWindowJSON{Spec: w.spec, Duration: w.duration, Location: w.location.String()})
+}
+
+func (w *MaintenanceWindow) UnmarshalJSON(data []byte) error {
+	var v maintenanceWindowJSON
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	location, err := time.LoadLocation(v.Location)
+	if err != nil {
+		return fmt.Errorf("%w: maintenance window %q: %w", ErrInvalidArgument, v.Spec, err)
+	}
+	parsed, err := ParseMaintenanceWindow(v.Spec, v.Duration, location)
+	if err != nil {
+		return err
+	}
+	*w = *parsed
+	return nil
+}
+
+// returns true if the schedule matches the day of the time
+func (w *MaintenanceWindow) matchesDay(t time.Time) bool {
+	dom, dow := w.dom[t.Day()], w.dow[t.Weekday()]
+	switch {
+	case w.anyDom && w.anyDow:
+		return true
+	case w.anyDom:
+		return dow
+	case w.anyDow:
+		return dom
+	}
+	return dom || dow
+}
+
+// returns true if the schedule opens the window at the minute of the time
+func (w *MaintenanceWindow) matches(t time.Time) bool {
+	return w.month[t.Month()] && w.matchesDay(t) && w.hour[t.Hour()] && w.minute[t.Minute()]
+}
+
+// returns true if the window is open at the time
+func (w *MaintenanceWindow) Open(t time.Time) bool {
+	t = t.In(w.location)
+	for start := t.Truncate(time.Minute); t.Sub(start) < w.duration; start = start.Add(-time.Minute) {
+		if w.matches(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the next time the window opens after the time, false is returned if the schedule never matches
+// within five years (for example the 31st of February)
+func (w *MaintenanceWindow) Next(t time.Time) (time.Time, bool) {
+	t = t.In(w.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case !w.month[m]:
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, w.location)
+		case !w.matchesDay(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, w.location)
+		case !w.hour[t.Hour()]:
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, w.location)
+		case !w.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// option to restrict state-changing commands to maintenance windows. Commands sent outside every window
+// return ErrOutsideMaintenanceWindow, or block until a window opens when the policy queues them
+func WithMaintenanceWindows(policy MaintenancePolicy) Option {
+	return func(cn *Client) {
+		cn.maintenance = &policy
+	}
+}
+
+// returns nil once the command may be sent, waiting for the next window when the policy queues commands
+func (p *MaintenancePolicy) wait(ctx context.Context, cmd string) error {
+	if p == nil || isQueryCommand(cmd) {
+		return nil
+	}
+
+	now := time.Now()
+	var next time.Time
+	for _, w := range p.Windows {
+		if w.Open(now) {
+			return nil
+		}
+		if t, ok := w.Next(now); ok && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	if !p.Queue || next.IsZero() {
+		return ErrOutsideMaintenanceWindow
+	}
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
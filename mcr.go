@@ -2,18 +2,25 @@ package mcr
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"time"
 )
 
 const (
-	//rcon packet type values
+	//request id returned by the server when authentication fails
 	FailurePacket = int32(-1)
-	CommandPacket = int32(2)
-	AuthPacket    = int32(3)
+
+	//rcon packet type values per the Source RCon standard, the auth response and command types share a value
+	//and are told apart by the direction of the packet
+	ResponsePacket     = 0 //SERVERDATA_RESPONSE_VALUE
+	AuthResponsePacket = 2 //SERVERDATA_AUTH_RESPONSE
+	CommandPacket      = 2 //SERVERDATA_EXECCOMMAND
+	AuthPacket         = 3 //SERVERDATA_AUTH
 
 	//tcp constants
 	Protocol          = "tcp"
@@ -22,34 +29,95 @@ const (
 	PacketPaddingSize = 2  //size of padding required after body
 
 	//default values
-	ResetID        = 1
-	DefaultCap     = 100
-	DefaultTimeout = time.Second * 10
-	DefaultPort    = 61695
+	ResetID                = 1
+	DefaultCap             = 100
+	DefaultTimeout         = time.Second * 10
+	DefaultPort            = 61695
+	DefaultFragmentTimeout = time.Millisecond * 100
+	DefaultDrainTimeout    = time.Second
+	DefaultDrainLimit      = 1 << 20
+	DefaultMaxResponseSize = 1 << 20
 )
 
 // remote console response headers
 type headers struct {
-	Size      int32 //size of packet
-	RequestID int32 //client-side request id
-	Type      int32 //type of packet
+	Size      int32      //size of packet
+	RequestID int32      //client-side request id
+	Type      PacketType //type of packet
 }
 
 // command response returned to client
-type response struct {
+type Response struct {
 	RequestID int32 //client-side request id
-	Type      int32
-	Body      string //response from server
+	Type      PacketType
+	Body      string        //response from server
+	Raw       []byte        //undecoded body bytes of every fragment with padding removed
+	Fragments int           //number of packets the response was split across
+	Sent      time.Time     //time the command was sent, set by CommandFull
+	Latency   time.Duration //time from sending the command until the full response was read, set by CommandFull
+	Outcome   Outcome       //classified server response, set by CommandFull
+}
+
+// joins the raw bytes of the next fragment of a split response, the body is decoded once every fragment has
+// arrived since characters and escapes can be split across fragments
+func (r *Response) append(next *Response) {
+	r.Raw = append(r.Raw, next.Raw...)
+	r.Fragments++
+}
+
+// returns true if the server sent no output for the command, this is not an error as many commands that
+// change server state reply with an empty body
+func (r *Response) Empty() bool {
+	return r.Body == ""
 }
 
 // remote console client
 type Client struct {
-	connection net.Conn      //server connection
-	requestID  int32         //self-incrementing request counter used for unique request id's
-	address    string        //server address
-	port       int           //server port
-	timeout    time.Duration //timeout for connection
-	cap        int32         //request id capacity before resetting it
+	connection      net.Conn            //server connection
+	requestID       int32               //self-incrementing request counter used for unique request id's
+	address         string              //server address
+	port            int                 //server port
+	timeout         time.Duration       //timeout for connection
+	cap             int32               //request id capacity before resetting it
+	noDelay         bool                //disables Nagle's algorithm on tcp connections
+	readTimeout     time.Duration       //deadline applied to each response read, zero disables it
+	fingerprint     string              //server software used to look up known quirks
+	quirks          Quirk               //quirks overriding the fingerprint lookup
+	quirksSet       bool                //true when quirks were supplied with WithQuirks
+	encoding        Encoding            //encoding used to decode response bodies
+	reassembly      ReassemblyStrategy  //strategy used to read responses split across multiple packets
+	fragmentTimeout time.Duration       //quiet period used to detect the end of a split response
+	stale           bool                //true when a failed request may have left packets on the connection
+	drainTimeout    time.Duration       //maximum time spent discarding stale packets
+	drainLimit      int                 //maximum bytes discarded before the connection is considered unrecoverable
+	maxResponseSize int                 //maximum body bytes accepted for a response
+	labels          map[string]string   //user supplied attributes identifying the connection
+	stats           rollingStats        //rolling command statistics
+	confirmation    *ConfirmationPolicy //commands requiring approval before they are sent
+	transport       Transport           //carries the byte stream to the server, TCPTransport when nil
+	readOnly        bool                //rejects commands that are not known queries
+	adaptive        *adaptiveTimeouts   //learned per-verb read deadlines
+	verbTimeout     time.Duration       //read deadline for the command in flight, overrides readTimeout when set
+	ctx             context.Context     //context of the operation in flight
+	password        string              //password of the last successful authentication, used to reconnect
+	reconnect       *reconnectPolicy    //redials broken connections when set
+	conformance     bool                //rejects responses deviating from the specification
+	duplicates      *duplicateGuard     //suppresses repeated state-changing commands when set
+	slowLog         *slowLog            //records commands slower than a threshold when set
+	strict          bool                //verifies response request ids match the request
+	broken          bool                //true when the connection broke during the last request
+	maintenance     *MaintenancePolicy  //restricts state-changing commands to maintenance windows when set
+	identityFormat  string              //format of the identity prefix added to broadcasts, empty disables it
+	trace           traceLog            //recent requests included in debug bundles
+	srvLookup       bool                //resolves the address SRV record when connecting
+	trim            TrimPolicy          //removes the bytes following the response body
+	localAddr       net.Addr            //source address of the tcp transport
+	keepAlive       time.Duration       //tcp keepalive period, negative disables keepalives and zero keeps the Go default
+	candidates      []PasswordProvider  //passwords tried in order when connecting, overrides the Connect argument
+	requirePassword bool                //rejects empty passwords instead of sending them
+	report          ConnectReport       //outcome of the last successful connection
+	sanitize        *SanitizePolicy     //removes or rejects unsafe characters in commands when set
+	session         *sessionTracker     //summarizes the session on Close when set
 }
 
 type IClient interface {
@@ -58,8 +126,10 @@ type IClient interface {
 	Command(cmd string) (string, error)
 	Close() error
 	//filtered methods
-	send(packet []byte) (*response, error)
-	createPacket(body []byte, packetType int32) ([]byte, error)
+	send(packet []byte) (*Response, error)
+	recv() (*Response, error)
+	roundTrip(packet []byte) (*Response, error)
+	createPacket(body []byte, packetType PacketType) ([]byte, error)
 	authenticate(password []byte) error
 	incrementRequestID()
 }
@@ -68,12 +138,17 @@ type IClient interface {
 // Connect method is called to authenticate the client. Check the README for information on default values
 func NewClient(addr string, opts ...Option) *Client {
 	c := &Client{
-		connection: nil,
-		requestID:  ResetID,
-		address:    addr,
-		port:       DefaultPort,
-		timeout:    DefaultTimeout,
-		cap:        DefaultCap,
+		connection:      nil,
+		requestID:       ResetID,
+		address:         addr,
+		port:            DefaultPort,
+		timeout:         DefaultTimeout,
+		cap:             DefaultCap,
+		noDelay:         true,
+		fragmentTimeout: DefaultFragmentTimeout,
+		drainTimeout:    DefaultDrainTimeout,
+		drainLimit:      DefaultDrainLimit,
+		maxResponseSize: DefaultMaxResponseSize,
 	}
 
 	for _, opt := range opts {
@@ -86,19 +161,77 @@ func NewClient(addr string, opts ...Option) *Client {
 // connects to server and authenticates the client. Ensure to call or defer the call to the Close method
 // to clean up the connection
 func (c *Client) Connect(password string) error {
+	return c.ConnectContext(context.Background(), password)
+}
+
+// connects to server and authenticates the client, the context bounds both the dial and authentication in
+// addition to the client timeout. Ensure to call or defer the call to the Close method to clean up the connection
+func (c *Client) ConnectContext(ctx context.Context, password string) error {
+	if len(c.candidates) > 0 {
+		return c.connectCandidates(ctx)
+	}
+
+	err := c.connect(ctx, password)
+	if err != nil {
+		return err
+	}
+	c.report = ConnectReport{Candidate: -1, Attempts: 1}
+	return nil
+}
+
+// dials the server if needed and authenticates with the password
+func (c *Client) connect(ctx context.Context, password string) error {
+	body, err := c.authBody(password)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
 	if c.connection == nil {
-		connection, err := net.DialTimeout(Protocol, fmt.Sprintf("%s:%d", c.address, c.port), c.timeout)
+		transport := c.transport
+		if transport == nil {
+			transport = &TCPTransport{Dialer: net.Dialer{LocalAddr: c.localAddr}}
+		}
+		connection, err := transport.Dial(ctx, Protocol, c.dialAddress(ctx))
 		if err != nil {
 			return err
 		}
 
+		if tcp, ok := connection.(*net.TCPConn); ok {
+			err = configureTCP(tcp, c.noDelay, c.keepAlive)
+			if err != nil {
+				connection.Close()
+				return err
+			}
+		}
+
 		c.connection = connection
 	}
 
-	err := c.authenticate([]byte(password))
+	unbind := c.bind(ctx)
+	defer unbind()
+
+	start := time.Now()
+	id := c.requestID
+	err = c.authenticate(body)
+	c.trace.record(TraceEntry{
+		At:        start,
+		RequestID: id,
+		Type:      SourcePacketTypes.Request(AuthPacket),
+		Command:   "<password redacted>",
+		Latency:   time.Since(start),
+		Err:       errorString(err),
+	})
+	if errors.Is(err, ErrAuthFailed) && (password == "" || password == EmptyPassword) {
+		return fmt.Errorf("%w: the server does not accept an empty password", err)
+	}
 	if err != nil {
-		return err
+		return closedError(contextError(ctx, err))
 	}
+	c.password = password
+	c.session.connected(c)
 
 	return nil
 }
@@ -106,71 +239,294 @@ func (c *Client) Connect(password string) error {
 // sends a command to the server and returns the server response, an error is returned if the client has
 // not connected to the server before attempting to send a command
 func (c *Client) Command(cmd string) (string, error) {
+	return c.CommandContext(context.Background(), cmd)
+}
+
+// sends a command to the server and returns the server response. Cancelling the context or reaching its
+// deadline interrupts the pending write or read, the stream is drained before the next command
+func (c *Client) CommandContext(ctx context.Context, cmd string) (string, error) {
+	res, err := c.CommandFullContext(ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+	return res.Body, nil
+}
+
+// sends a command to the server and returns the complete response including the request id, packet type,
+// raw body bytes, and timing for callers doing their own correlation and diagnostics
+func (c *Client) CommandFull(cmd string) (*Response, error) {
+	return c.CommandFullContext(context.Background(), cmd)
+}
+
+// sends a command to the server and returns the complete response, the context is handled like CommandContext
+func (c *Client) CommandFullContext(ctx context.Context, cmd string) (*Response, error) {
 	if c.connection == nil {
-		return "", errors.New("the Connect method must be called before commands can be run")
+		return nil, ErrNotConnected
 	}
 
-	packet, err := c.createPacket([]byte(cmd), CommandPacket)
+	if identity, ok := IdentityFrom(ctx); ok && c.identityFormat != "" {
+		cmd = prefixIdentity(cmd, c.identityFormat, identity)
+	}
+
+	//sanitized after the prefix so identities cannot smuggle control characters past the policy
+	cmd, err := c.sanitize.apply(cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	res, err := c.send(packet)
+	if c.readOnly && !isQueryCommand(cmd) {
+		return nil, ErrReadOnly
+	}
+
+	if !c.confirmation.approve(cmd) {
+		return nil, ErrNotConfirmed
+	}
+
+	err = c.maintenance.wait(ctx, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return res.Body, nil
+	if !c.duplicates.check(cmd, time.Now()) {
+		return nil, ErrDuplicateCommand
+	}
+
+	var verb string
+	if c.adaptive != nil {
+		verb = commandVerb(cmd)
+		c.verbTimeout = c.adaptive.timeout(verb)
+		defer func() { c.verbTimeout = 0 }()
+	}
+
+	start := time.Now()
+	res, err := c.exchange(ctx, []byte(cmd))
+	if err != nil && c.reconnect != nil && isBroken(err) {
+		//the server may have run the command before the connection broke so only queries are sent again, a
+		//truncated response proves the command ran
+		retry := isQueryCommand(cmd) && !errors.Is(err, ErrTruncatedResponse)
+		rerr := c.redial(ctx)
+		switch {
+		case rerr != nil:
+			err = errors.Join(err, rerr)
+		case retry:
+			res, err = c.exchange(ctx, []byte(cmd))
+		}
+	}
+	latency := time.Since(start)
+	c.stats.record(time.Now(), latency, err != nil)
+	c.session.command(cmd, res, err)
+	if c.slowLog != nil {
+		entry := SlowCommand{Command: cmd, At: start, Latency: latency, Fingerprint: c.fingerprint, Err: err}
+		if res != nil {
+			entry.ResponseSize, entry.Fragments = len(res.Raw), res.Fragments
+		}
+		c.slowLog.record(entry)
+	}
+	entry := TraceEntry{At: start, Type: SourcePacketTypes.Request(CommandPacket), Command: cmd, Latency: latency, Err: errorString(err)}
+	if res != nil {
+		entry.RequestID, entry.ResponseBytes, entry.Fragments = res.RequestID, len(res.Raw), res.Fragments
+	}
+	c.trace.record(entry)
+	if c.adaptive != nil {
+		var ne net.Error
+		switch {
+		case err == nil:
+			c.adaptive.record(verb, latency)
+		case errors.As(err, &ne) && ne.Timeout():
+			c.adaptive.record(verb, c.verbTimeout) //extend the next deadline for this verb
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.duplicates.record(cmd, start)
+
+	res.Sent = start
+	res.Latency = latency
+	res.Outcome = Classify(res.Body)
+	return res, nil
 }
 
-// closes remote console connection, nil's out the connection value in client struct, and resets the request id
+// sends a single command packet bound to the context and reads the response
+func (c *Client) exchange(ctx context.Context, cmd []byte) (*Response, error) {
+	packet, err := c.createPacket(cmd, CommandPacket)
+	if err != nil {
+		return nil, err
+	}
+
+	unbind := c.bind(ctx)
+	defer unbind()
+
+	res, err := c.roundTrip(packet)
+	err = closedError(contextError(ctx, err))
+	c.broken = errors.Is(err, ErrConnectionClosed)
+	return res, err
+}
+
+// closes remote console connection, nil's out the connection value in client struct, and resets the request id.
+// The session summary is sent when WithSessionSummary is set
 func (c *Client) Close() error {
+	c.session.end()
+	return c.disconnect()
+}
+
+// closes the connection without ending the session, used when the client reconnects in place
+func (c *Client) disconnect() error {
 	c.requestID = ResetID
+	c.stale = false
+	c.broken = false
 	if c.connection != nil {
 		err := c.connection.Close()
+		c.connection = nil //dropped even when closing fails, a connection the server closed cannot be reused
 		if err != nil {
 			return err
 		}
-		c.connection = nil
 	}
 	return nil
 }
 
+// returns a copy of the labels attached to the client with WithLabels
+func (c *Client) Labels() map[string]string {
+	labels := make(map[string]string, len(c.labels))
+	for k, v := range c.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
 // constructs and sends the tcp packet to the server and parses the response data, requestID is incremented
 // after each packet is sent
-func (c *Client) send(packet []byte) (*response, error) {
+func (c *Client) send(packet []byte) (*Response, error) {
+	id := c.requestID
 	_, err := c.connection.Write(packet)
 	if err != nil {
 		return nil, err
 	}
 
+	res, err := c.recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.strict {
+		auth := PacketType(binary.LittleEndian.Uint32(packet[8:12])) == AuthPacket
+		res, err = c.correlate(res, id, auth)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.incrementRequestID()
+
+	return res, nil
+}
+
+// reads a single response packet from the server, any active quirks are applied while parsing the packet
+func (c *Client) recv() (*Response, error) {
+	timeout := c.readTimeout
+	if c.verbTimeout > 0 {
+		timeout = c.verbTimeout
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if c.ctx != nil {
+		if d, ok := c.ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+			deadline = d
+		}
+	}
+	return c.readResponse(deadline, false)
+}
+
+// reads a single response packet before the deadline. When quiet is true a deadline that expires before any
+// bytes arrive is not an error and a nil response is returned
+func (c *Client) readResponse(deadline time.Time, quiet bool) (*Response, error) {
+	err := c.connection.SetReadDeadline(deadline)
+	if err != nil {
+		return nil, err
+	}
+	defer c.connection.SetReadDeadline(time.Time{})
+	if c.ctx != nil && c.ctx.Err() != nil { //cancelled before the deadline was set, see bind
+		return nil, c.ctx.Err()
+	}
+
+	head := make([]byte, PacketHeaderSize+4) //headers including the Size header
+	n, err := io.ReadFull(c.connection, head)
+	if err != nil {
+		var ne net.Error
+		if quiet && n == 0 && errors.As(err, &ne) && ne.Timeout() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
 	var res headers
-	err = binary.Read(c.connection, binary.LittleEndian, &res)
+	err = binary.Read(bytes.NewReader(head), binary.LittleEndian, &res)
 	if err != nil {
 		return nil, err
 	}
 
-	payload := make([]byte, res.Size-PacketHeaderSize) //read body size (total size - header size)
-	err = binary.Read(c.connection, binary.LittleEndian, &payload)
+	if c.conformance {
+		err = checkSize(res.Size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	quirks := c.activeQuirks()
+	size := res.Size - PacketHeaderSize //read body size (total size - header size)
+	if quirks.Has(QuirkSizeExcludesPadding) {
+		size += PacketPaddingSize
+	}
+
+	//validate the size before allocating, a hostile server could announce gigabytes or a negative body
+	if size < 0 {
+		return nil, fmt.Errorf("%w: Size header %d is smaller than the packet headers", ErrInvalidPacketSize, res.Size)
+	}
+	if int(size) > c.maxResponseSize+PacketPaddingSize {
+		return nil, fmt.Errorf("%w: Size header announces %d body bytes, the limit is %d", ErrResponseTooLarge, size, c.maxResponseSize)
+	}
+
+	payload := make([]byte, size)
+	n, err = io.ReadFull(c.connection, payload)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, &TruncatedResponseError{Partial: payload[:n], Expected: len(payload)}
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	//remove byte padding
-	payload = payload[:len(payload)-2]
+	if c.conformance && !quirks.Has(QuirkNoPadding) {
+		err = checkBody(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	c.incrementRequestID()
+	payload = trimBody(payload, c.trim, quirks)
+
+	_, err = SourcePacketTypes.decodeResponse(res.Type)
+	if err != nil && quirks.Has(QuirkServerPush) { //unsolicited packet, the response follows
+		return c.readResponse(deadline, quiet)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	return &response{
+	return &Response{
 		RequestID: res.RequestID,
 		Type:      res.Type,
-		Body:      string(payload),
+		Body:      decodeBody(payload, c.encoding),
+		Raw:       payload,
+		Fragments: 1,
 	}, nil
 }
 
 // creates remote console packet including the body and packet type returning the packet bytes. These bytes
 // can be sent directly to the server.
-func (c *Client) createPacket(body []byte, packetType int32) ([]byte, error) {
+func (c *Client) createPacket(body []byte, packetType PacketType) ([]byte, error) {
 	length := len(body) + PacketRequestSize
 
 	//packet structure
@@ -207,6 +563,7 @@ func (c *Client) createPacket(body []byte, packetType int32) ([]byte, error) {
 // sends authentication packet to server. This must be called before
 // any commands can be run and returns an error if the supplied password is incorrect
 func (c *Client) authenticate(password []byte) error {
+	id := c.requestID
 	packet, err := c.createPacket(password, AuthPacket)
 	if err != nil {
 		return err
@@ -217,8 +574,23 @@ func (c *Client) authenticate(password []byte) error {
 		return err
 	}
 
+	//some servers send an empty response value packet ahead of the auth response
+	if c.activeQuirks().Has(QuirkAuthJunkPacket) && res.Type == ResponsePacket {
+		res, err = c.recv()
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.conformance {
+		err = checkResponse(res, id, AuthResponsePacket)
+		if err != nil {
+			return err
+		}
+	}
+
 	if res.RequestID == FailurePacket { //request id is set to -1 if auth fails
-		return errors.New("authentication failed")
+		return ErrAuthFailed
 	}
 
 	return nil
@@ -1,12 +1,21 @@
 package mcr
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"testing/quick"
 	"time"
 )
 
@@ -224,3 +233,2077 @@ func TestAuthenticationUsingConnect(t *testing.T) {
 	serv.Close()
 	recv.Close()
 }
+
+// testing the latency profile presets
+func TestLatencyProfileOption(t *testing.T) {
+	tc := NewClient("test", WithLatencyProfile(LatencyBulk))
+	if tc.noDelay || tc.readTimeout != time.Second*30 {
+		t.Fatal("bulk profile did not update client settings")
+	}
+	tc = NewClient("test", WithLatencyProfile(LatencyInteractive))
+	if !tc.noDelay || tc.readTimeout != time.Second*5 {
+		t.Fatal("interactive profile did not update client settings")
+	}
+}
+
+// writes a raw response packet to the mock connection
+func writeTestPacket(conn net.Conn, requestID int32, packetType PacketType, body []byte) error {
+	var head headers
+	head.Size = int32(len(body) + PacketRequestSize)
+	head.RequestID = requestID
+	head.Type = packetType
+	err := binary.Write(conn, binary.LittleEndian, head)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(append(body, make([]byte, PacketPaddingSize)...))
+	return err
+}
+
+// reads a raw request packet from the mock connection
+func readTestPacket(conn net.Conn) (headers, []byte, error) {
+	var head headers
+	err := binary.Read(conn, binary.LittleEndian, &head)
+	if err != nil {
+		return head, nil, err
+	}
+	body := make([]byte, head.Size-PacketHeaderSize)
+	err = binary.Read(conn, binary.LittleEndian, &body)
+	if err != nil {
+		return head, nil, err
+	}
+	return head, body[:len(body)-PacketPaddingSize], nil
+}
+
+// testing the auth junk packet quirk applied from the source fingerprint
+func TestAuthJunkPacketQuirk(t *testing.T) {
+	serv, recv := net.Pipe()
+	defer serv.Close()
+	testingClient := NewClient("testing", WithFingerprint("source"))
+	testingClient.connection = recv
+
+	go func() {
+		head, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		writeTestPacket(serv, head.RequestID, ResponsePacket, nil)
+		writeTestPacket(serv, FailurePacket, CommandPacket, nil)
+	}()
+
+	err := testingClient.Connect("password")
+	if err == nil {
+		t.Fatal("auth failure after junk packet was not detected")
+	}
+	testingClient.Close()
+}
+
+// testing the quirk overrides
+func TestQuirksOption(t *testing.T) {
+	tc := NewClient("test", WithQuirks(QuirkNoPadding), WithFingerprint("source"))
+	if tc.activeQuirks() != QuirkNoPadding {
+		t.Fatal("quirks override was not applied")
+	}
+	q, ok := LookupQuirks("source")
+	if !ok || !q.Has(QuirkAuthJunkPacket) {
+		t.Fatal("source fingerprint is missing the auth junk packet quirk")
+	}
+	for fp, q := range quirkRegistry {
+		if q == 0 {
+			t.Fatalf("fingerprint %q is registered without a quirk", fp)
+		}
+	}
+}
+
+// testing response body decoding for each supported encoding
+func TestDecodeBody(t *testing.T) {
+	cases := []struct {
+		payload []byte
+		enc     Encoding
+		want    string
+	}{
+		{[]byte("Jörg joined"), EncodingAuto, "Jörg joined"},
+		{[]byte{'J', 0xf6, 'r', 'g'}, EncodingAuto, "Jörg"},
+		{[]byte(`J\u00f6rg \ud83d\ude00`), EncodingAuto, "Jörg 😀"},
+		{[]byte(`J\u00f6rg`), EncodingUTF8, `J\u00f6rg`},
+		{[]byte("Jö"), EncodingLatin1, "JÃ¶"},
+		{[]byte(`bad \u00zz`), EncodingEscaped, `bad \u00zz`},
+	}
+	for _, c := range cases {
+		if got := decodeBody(c.payload, c.enc); got != c.want {
+			t.Fatalf("decoded %q as %q, expected %q", c.payload, got, c.want)
+		}
+	}
+}
+
+// testing fragmented responses using the sentinel reassembly strategy
+func TestReassemblySentinel(t *testing.T) {
+	serv, recv := net.Pipe()
+	defer serv.Close()
+	testingClient := NewClient("testing", WithReassemblyStrategy(ReassemblySentinel), WithFragmentTimeout(time.Millisecond*10))
+	testingClient.connection = recv
+
+	go func() {
+		cmd, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		sentinel, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte("first "))
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte("second"))
+		writeTestPacket(serv, sentinel.RequestID, ResponsePacket, nil)
+		writeTestPacket(serv, sentinel.RequestID, ResponsePacket, []byte{0, 1, 0, 0})
+	}()
+
+	res, err := testingClient.Command("help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "first second" {
+		t.Fatalf("fragments were not reassembled, got %q", res)
+	}
+	testingClient.Close()
+}
+
+// testing fragmented responses using the timeout reassembly strategy
+func TestReassemblyTimeout(t *testing.T) {
+	serv, recv := net.Pipe()
+	defer serv.Close()
+	testingClient := NewClient("testing", WithReassemblyStrategy(ReassemblyTimeout), WithFragmentTimeout(time.Millisecond*50))
+	testingClient.connection = recv
+
+	go func() {
+		cmd, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte("first "))
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte("second"))
+	}()
+
+	res, err := testingClient.Command("help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "first second" {
+		t.Fatalf("fragments were not reassembled, got %q", res)
+	}
+	testingClient.Close()
+}
+
+// testing that stale responses left by a timed out command are drained before the next command
+func TestDrainAfterTimeout(t *testing.T) {
+	serv, recv := net.Pipe()
+	defer serv.Close()
+	testingClient := NewClient("testing", WithFragmentTimeout(time.Millisecond*20))
+	testingClient.readTimeout = time.Millisecond * 20
+	testingClient.connection = recv
+
+	go func() {
+		cmd, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		time.Sleep(time.Millisecond * 30)
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte("late"))
+		cmd, _, err = readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte("fresh"))
+	}()
+
+	_, err := testingClient.Command("slow")
+	if err == nil {
+		t.Fatal("expected the first command to time out")
+	}
+	time.Sleep(time.Millisecond * 60)
+
+	res, err := testingClient.Command("fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "fresh" {
+		t.Fatalf("stale response was not drained, got %q", res)
+	}
+	testingClient.Close()
+}
+
+// testing the labels option merges labels and returns a copy
+func TestLabelsOption(t *testing.T) {
+	tc := NewClient("test", WithLabels(map[string]string{"env": "prod"}), WithLabels(map[string]string{"shard": "3"}))
+	labels := tc.Labels()
+	if len(labels) != 2 || labels["env"] != "prod" || labels["shard"] != "3" {
+		t.Fatal("labels were not merged")
+	}
+	labels["env"] = "dev"
+	if tc.Labels()["env"] != "prod" {
+		t.Fatal("labels returned by the client are not a copy")
+	}
+}
+
+// testing a settings handoff between two clients
+func TestExportImport(t *testing.T) {
+	tc := NewClient("test", WithPort(9876), WithLabels(map[string]string{"env": "prod"}), WithQuirks(QuirkNoPadding))
+	tc.requestID = 42
+	data, err := tc.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported := NewClient("other")
+	err = imported.Import(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.address != "test" || imported.port != 9876 || imported.requestID != 42 ||
+		imported.Labels()["env"] != "prod" || imported.activeQuirks() != QuirkNoPadding {
+		t.Fatal("imported settings do not match the exported client")
+	}
+
+	//every config field is set so a field missing from the export or import fails the comparison
+	window, err := ParseMaintenanceWindow("0 3 * * 0", 2*time.Hour, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc = NewClient("test",
+		WithPort(9876),
+		WithTimeout(time.Second),
+		WithCap(50),
+		WithLatencyProfile(LatencyInteractive),
+		WithFingerprint("paper"),
+		WithQuirks(QuirkNoPadding),
+		WithEncoding(EncodingLatin1),
+		WithReassemblyStrategy(ReassemblySentinel),
+		WithFragmentTimeout(time.Millisecond*20),
+		WithDrainLimits(time.Second, 1024),
+		WithMaxResponseSize(4096),
+		WithLabels(map[string]string{"env": "prod"}),
+		WithReadOnly(),
+		WithConformance(),
+		WithStrictCorrelation(),
+		WithIdentityPrefix("<%s> "),
+		WithSRVLookup(),
+		WithTrimPolicy(TrimWhitespace),
+		WithKeepAlive(time.Minute),
+		WithSanitization(SanitizePolicy{Reject: true}),
+		WithMaintenanceWindows(MaintenancePolicy{Windows: []*MaintenanceWindow{window}, Queue: true}),
+		WithDuplicateSuppression(DuplicatePolicy{Window: time.Minute}),
+		WithLocalAddr(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}),
+		WithRequirePassword(),
+		WithAutoReconnect(time.Millisecond, time.Second, 3),
+		WithAdaptiveTimeouts(time.Second, time.Minute),
+		WithFullTrace(),
+	)
+	cfg := reflect.ValueOf(tc.Config())
+	for i := 0; i < cfg.NumField(); i++ {
+		if cfg.Field(i).IsZero() {
+			t.Fatalf("config field %s is not covered by the test", cfg.Type().Field(i).Name)
+		}
+	}
+
+	data, err = tc.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported = NewClient("other")
+	err = imported.Import(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(imported.Config(), tc.Config()) {
+		t.Fatalf("imported config does not match\n%+v\n%+v", imported.Config(), tc.Config())
+	}
+}
+
+// testing response classification against common server messages
+func TestClassify(t *testing.T) {
+	cases := map[string]Outcome{
+		"Kicked Steve: Kicked by an operator":                               OutcomeSuccess,
+		"Unknown or incomplete command, see below for error<--[HERE]":       OutcomeUnknownCommand,
+		"I'm sorry, but you do not have permission to perform this command": OutcomeNoPermission,
+		"No player was found":            OutcomePlayerNotFound,
+		"Incorrect argument for command": OutcomeSyntaxError,
+		"§cNo player was found":          OutcomePlayerNotFound,
+		"Expected whitespace to end one argument, but found trailing data...tp @s ~ ~ ~x<--[HERE]": OutcomeSyntaxError,
+		"There are 1 of a max of 20 players online: invalid_name":                                  OutcomeSuccess,
+		"Steve has the following entity data: \"expected\"":                                        OutcomeSuccess,
+		"<Steve> the key cannot be found, no player was found either":                              OutcomeSuccess,
+	}
+	for body, want := range cases {
+		if got := Classify(body); got != want {
+			t.Fatalf("classified %q as %s, expected %s", body, got, want)
+		}
+	}
+}
+
+// testing bulk player operations report results per player
+func TestBulkKick(t *testing.T) {
+	serv, recv := net.Pipe()
+	defer serv.Close()
+	testingClient := NewClient("testing")
+	testingClient.connection = recv
+
+	go func() {
+		for {
+			head, body, err := readTestPacket(serv)
+			if err != nil {
+				return
+			}
+			reply := "Kicked Steve: Kicked by an operator"
+			if string(body) == "kick Alex" {
+				reply = "No player was found"
+			}
+			writeTestPacket(serv, head.RequestID, ResponsePacket, []byte(reply))
+		}
+	}()
+
+	results := testingClient.BulkKick([]string{"Steve", "Alex", "bad name"})
+	if !results["Steve"].OK() {
+		t.Fatal("expected Steve to be kicked")
+	}
+	if results["Alex"].Outcome != OutcomePlayerNotFound {
+		t.Fatal("expected Alex to be reported as not found")
+	}
+	if !errors.Is(results["bad name"].Err, ErrInvalidArgument) {
+		t.Fatal("expected invalid player name to be rejected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = testingClient.BulkKickContext(ctx, []string{"Steve"})
+	if !errors.Is(results["Steve"].Err, context.Canceled) {
+		t.Fatalf("expected cancelled bulk kick to skip Steve, got %v", results["Steve"].Err)
+	}
+	testingClient.Close()
+}
+
+// testing placeholder resolution against list and tps responses
+func TestResolve(t *testing.T) {
+	serv, recv := net.Pipe()
+	defer serv.Close()
+	testingClient := NewClient("testing")
+	testingClient.connection = recv
+
+	go func() {
+		for {
+			head, body, err := readTestPacket(serv)
+			if err != nil {
+				return
+			}
+			reply := "There are 1 of a max of 20 players online: Steve"
+			if string(body) == "tps" {
+				reply = "§6TPS from last 1m, 5m, 15m: §a*20.0, §a19.5, §a19.9"
+			}
+			writeTestPacket(serv, head.RequestID, ResponsePacket, []byte(reply))
+		}
+	}()
+
+	res, err := testingClient.Resolve("say {online_count}/{max_players} {random_player} {tps} {other}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "say 1/20 Steve 20.0 {other}" {
+		t.Fatalf("placeholders were not resolved, got %q", res)
+	}
+	testingClient.Close()
+
+	//the test server echoes commands, which is not a list response
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port))
+	err = tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	_, err = tc.Resolve("say {online_count}")
+	if !errors.Is(err, ErrUnrecognizedResponse) {
+		t.Fatalf("expected an unrecognized response, got %v", err)
+	}
+}
+
+// testing rolling window statistics only include commands inside each window
+func TestRollingStats(t *testing.T) {
+	var rs rollingStats
+	now := time.Now()
+	rs.record(now, time.Millisecond*10, false)
+	rs.record(now, time.Millisecond*30, false)
+	rs.record(now.Add(-time.Minute*2), 0, true)
+	rs.record(now.Add(-time.Minute*20), 0, true)
+
+	one := rs.window(now, time.Minute)
+	if one.Commands != 2 || one.Errors != 0 || one.AvgLatency != time.Millisecond*20 {
+		t.Fatalf("unexpected one minute stats %+v", one)
+	}
+	five := rs.window(now, time.Minute*5)
+	if five.Commands != 3 || five.Errors != 1 {
+		t.Fatalf("unexpected five minute stats %+v", five)
+	}
+	fifteen := rs.window(now, time.Minute*15)
+	if fifteen.Commands != 3 {
+		t.Fatalf("expired commands were included in the fifteen minute stats %+v", fifteen)
+	}
+}
+
+// testing dangerous commands are rejected unless confirmed
+func TestConfirmationPolicy(t *testing.T) {
+	asked := ""
+	tc := NewClient("testing", WithConfirmation(ConfirmationPolicy{
+		Patterns: DangerousCommands,
+		Confirm: func(cmd string) bool {
+			asked = cmd
+			return false
+		},
+	}))
+	tc.connection, _ = net.Pipe()
+	defer tc.Close()
+
+	_, err := tc.Command("/stop")
+	if err == nil || asked != "/stop" {
+		t.Fatal("stop command was sent without confirmation")
+	}
+	if !tc.confirmation.approve("list") {
+		t.Fatal("list command should not require confirmation")
+	}
+	for _, cmd := range []string{"  /STOP", "minecraft:ban Steve", "whitelist  remove Steve",
+		"execute as @a run deop @s", "execute if entity @p run /minecraft:stop"} {
+		if tc.confirmation.approve(cmd) {
+			t.Fatalf("%q was sent without confirmation", cmd)
+		}
+	}
+	for _, cmd := range []string{"banlist", "pardon Steve", "pardon-ip 10.0.0.1", "say stopping soon", "execute as @a run say hi"} {
+		if !tc.confirmation.approve(cmd) {
+			t.Fatalf("%q should not require confirmation", cmd)
+		}
+	}
+
+	//custom patterns anchored to the start also apply to execute chains
+	policy := &ConfirmationPolicy{Patterns: []*regexp.Regexp{regexp.MustCompile(`^kill\b`)}}
+	if policy.approve("execute as @a run kill @s") {
+		t.Fatal("execute chain was sent without confirmation")
+	}
+}
+
+// connection reading from an in-memory packet stream
+type bufferConn struct {
+	net.Conn
+	r *bytes.Reader
+}
+
+func (b *bufferConn) Read(p []byte) (int, error)        { return b.r.Read(p) }
+func (b *bufferConn) SetReadDeadline(t time.Time) error { return nil }
+
+// encodes a packet and decodes it with the client reader
+func roundTripPacket(body []byte, packetType PacketType, requestID int32) (*Response, error) {
+	tc := NewClient("testing", WithEncoding(EncodingUTF8))
+	tc.requestID = requestID
+	packet, err := tc.createPacket(body, packetType)
+	if err != nil {
+		return nil, err
+	}
+	tc.connection = &bufferConn{r: bytes.NewReader(packet)}
+	return tc.recv()
+}
+
+// property test asserting arbitrary packets survive an encode and decode round trip
+func TestPacketRoundTripProperty(t *testing.T) {
+	types := []PacketType{ResponsePacket, AuthResponsePacket}
+	property := func(body []byte, typeIndex uint8, requestID int32) bool {
+		packetType := types[int(typeIndex)%len(types)]
+		res, err := roundTripPacket(body, packetType, requestID)
+		return err == nil && res.Body == string(body) && res.Type == packetType && res.RequestID == requestID
+	}
+	err := quick.Check(property, &quick.Config{MaxCount: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//body sizes around the empty body and the 4096 byte response fragment limit
+	for _, size := range []int{0, 1, 4095, 4096, 4097} {
+		body := bytes.Repeat([]byte{'a'}, size)
+		res, err := roundTripPacket(body, CommandPacket, ResetID)
+		if err != nil || res.Body != string(body) {
+			t.Fatalf("round trip failed for a %d byte body", size)
+		}
+	}
+}
+
+// testing a server closing the connection mid-response returns the partial body
+func TestTruncatedResponse(t *testing.T) {
+	serv, recv := net.Pipe()
+	testingClient := NewClient("testing")
+	testingClient.connection = recv
+
+	go func() {
+		head, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		binary.Write(serv, binary.LittleEndian, headers{Size: 20, RequestID: head.RequestID, Type: ResponsePacket})
+		serv.Write([]byte("part"))
+		serv.Close()
+	}()
+
+	_, err := testingClient.Command("list")
+	var te *TruncatedResponseError
+	if !errors.Is(err, ErrTruncatedResponse) || !errors.As(err, &te) {
+		t.Fatalf("expected a truncated response error, got %v", err)
+	}
+	if string(te.Partial) != "part" || te.Expected != 12 {
+		t.Fatalf("unexpected partial response %+v", te)
+	}
+	testingClient.Close()
+}
+
+// testing read-only clients reject mutating commands
+func TestReadOnlyOption(t *testing.T) {
+	tc := NewClient("testing", WithReadOnly())
+	tc.connection, _ = net.Pipe()
+	defer tc.Close()
+
+	_, err := tc.Command("data merge entity @p {}")
+	if err == nil {
+		t.Fatal("mutating command was allowed on a read-only client")
+	}
+	for _, cmd := range []string{"list", "/data get entity Steve", "whitelist list"} {
+		if !isQueryCommand(cmd) {
+			t.Fatalf("query command %q was rejected", cmd)
+		}
+	}
+}
+
+// testing unsafe characters are removed or rejected according to the sanitization policy
+func TestSanitization(t *testing.T) {
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port), WithSanitization(SanitizePolicy{}))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	res, err := tc.Command("say hi\nop Steve §k\x00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "say hiop Steve k" {
+		t.Fatalf("unsafe characters were not removed, got %q", res)
+	}
+
+	tc.sanitize = &SanitizePolicy{Reject: true, AllowSectionSign: true}
+	res, err = tc.Command("say §chello")
+	if err != nil || res != "say §chello" {
+		t.Fatalf("section sign was not allowed, got %q %v", res, err)
+	}
+	_, err = tc.Command("say hi\rop Steve")
+	if !errors.Is(err, ErrUnsafeCommand) {
+		t.Fatalf("expected unsafe command, got %v", err)
+	}
+
+	//identities are checked as part of the final command
+	tc.identityFormat = DefaultIdentityFormat
+	_, err = tc.CommandContext(WithIdentity(context.Background(), "alice\nop Steve"), "say hi")
+	if !errors.Is(err, ErrUnsafeCommand) {
+		t.Fatalf("expected unsafe identity, got %v", err)
+	}
+}
+
+// testing moderation actions use the syntax of the detected plugin
+func TestModeration(t *testing.T) {
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	ctx := context.Background()
+	m, err := tc.DetectModeration(ctx) //the test server echoes "plugins" which names no moderation plugin
+	if err != nil || m.Syntax() != SyntaxVanilla {
+		t.Fatalf("expected vanilla syntax, got %v %v", m, err)
+	}
+	res, err := m.Ban(ctx, "Steve", "griefing")
+	if err != nil || res.Command != "ban Steve griefing" || !res.OK() {
+		t.Fatalf("unexpected ban result %+v %v", res, err)
+	}
+	_, err = m.TempBan(ctx, "Steve", 0, "griefing")
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected a zero duration temporary ban to be rejected, got %v", err)
+	}
+	_, err = m.Mute(ctx, "Steve", time.Hour, "spam")
+	if !errors.Is(err, ErrUnsupportedAction) {
+		t.Fatalf("expected unsupported mute, got %v", err)
+	}
+
+	m = tc.Moderation(SyntaxEssentials)
+	res, err = m.TempBan(ctx, "Steve", 36*time.Hour, "griefing")
+	if err != nil || res.Response != "tempban Steve 1d12h griefing" {
+		t.Fatalf("unexpected tempban result %+v %v", res, err)
+	}
+	res, err = m.Kick(ctx, "Steve", "")
+	if err != nil || res.Command != "kick Steve" {
+		t.Fatalf("unexpected kick result %+v %v", res, err)
+	}
+	_, err = m.Warn(ctx, "bad name", "spam")
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("expected invalid player name to be rejected")
+	}
+}
+
+// testing moderation durations round trip
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30m":     30 * time.Minute,
+		"1d12h":   36 * time.Hour,
+		"2w":      14 * 24 * time.Hour,
+		"1h30m5s": time.Hour + 30*time.Minute + 5*time.Second,
+	}
+	for s, want := range cases {
+		got, err := ParseDuration(s)
+		if err != nil || got != want {
+			t.Fatalf("parsed %q as %v %v, expected %v", s, got, err, want)
+		}
+		if FormatDuration(got) != s {
+			t.Fatalf("formatted %v as %q, expected %q", got, FormatDuration(got), s)
+		}
+	}
+	for _, s := range []string{"", "d", "5", "5y"} {
+		if _, err := ParseDuration(s); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("expected %q to be rejected", s)
+		}
+	}
+}
+
+// testing profiles bundle settings and the squad profile skips pushed chat packets
+func TestProfile(t *testing.T) {
+	tc := NewClient("testing", WithProfile(ProfileARK))
+	cfg := tc.Config()
+	if cfg.Fingerprint != "ark" || cfg.Reassembly != ReassemblyNone || cfg.Trim != TrimNulls || cfg.KeepAlive != time.Second*10 {
+		t.Fatalf("profile was not applied, got %+v", cfg)
+	}
+
+	serv, recv := net.Pipe()
+	defer serv.Close()
+	tc = NewClient("testing", WithProfile(ProfileSquad), WithReassemblyStrategy(ReassemblyNone))
+	tc.connection = recv
+	defer tc.Close()
+
+	go func() {
+		head, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		writeTestPacket(serv, 0, PacketType(1), []byte("[ChatAll] player: hi"))
+		writeTestPacket(serv, head.RequestID, ResponsePacket, []byte("ok"))
+	}()
+	res, err := tc.Command("ListPlayers")
+	if err != nil || res != "ok" {
+		t.Fatalf("pushed packet was not skipped, got %q %v", res, err)
+	}
+}
+
+// testing the session summary spans reconnects and is sent once on Close
+func TestSessionSummary(t *testing.T) {
+	host, port := startTestServer(t)
+	var summaries []SessionSummary
+	tc := NewClient(host, WithPort(port), WithLabels(map[string]string{"panel": "a"}),
+		WithSessionSummary(func(s SessionSummary) { summaries = append(summaries, s) }))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tc.Command("list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tc.Reconnect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tc.Command("seed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 0 {
+		t.Fatal("reconnecting ended the session")
+	}
+
+	tc.Close()
+	tc.Close()
+	if len(summaries) != 1 {
+		t.Fatalf("expected one summary, got %d", len(summaries))
+	}
+	s := summaries[0]
+	if s.Commands != 2 || s.Errors != 0 || s.Reconnects != 1 || s.BytesSent != 8 || s.BytesReceived != 8 {
+		t.Fatalf("unexpected summary %+v", s)
+	}
+	if s.Labels["panel"] != "a" || s.Duration <= 0 {
+		t.Fatalf("unexpected summary %+v", s)
+	}
+	//failed password candidates during a reconnect do not end the session
+	host, port = startAuthTestServer(t, "right")
+	summaries = nil
+	tc = NewClient(host, WithPort(port), WithPasswordCandidates(StaticPassword("right")),
+		WithSessionSummary(func(s SessionSummary) { summaries = append(summaries, s) }))
+	err = tc.Connect("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.candidates = []PasswordProvider{StaticPassword("wrong"), StaticPassword("right")}
+	err = tc.Reconnect("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 0 {
+		t.Fatal("a failed password candidate ended the session")
+	}
+	tc.Close()
+	if len(summaries) != 1 || summaries[0].Reconnects != 1 {
+		t.Fatalf("unexpected summaries %+v", summaries)
+	}
+}
+
+// testing empty response bodies including packets too short to hold the padding bytes
+func TestEmptyResponse(t *testing.T) {
+	for _, size := range []int32{PacketHeaderSize, PacketHeaderSize + 1, PacketRequestSize} {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, headers{Size: size, RequestID: ResetID, Type: ResponsePacket})
+		buf.Write(make([]byte, size-PacketHeaderSize))
+
+		tc := NewClient("testing")
+		tc.connection = &bufferConn{r: bytes.NewReader(buf.Bytes())}
+		res, err := tc.recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Empty() {
+			t.Fatalf("expected an empty response for size %d, got %q", size, res.Body)
+		}
+	}
+}
+
+// testing adaptive deadlines grow for slow verbs and stay within bounds
+func TestAdaptiveTimeouts(t *testing.T) {
+	tc := NewClient("testing", WithAdaptiveTimeouts(time.Second, time.Second*30))
+	a := tc.adaptive
+	if a.timeout("list") != time.Second {
+		t.Fatal("verbs without history should use the minimum deadline")
+	}
+	a.record(commandVerb("/locate structure village"), time.Second*4)
+	if a.timeout("locate") != time.Second*8 {
+		t.Fatal("slow verb deadline was not extended")
+	}
+	a.record("locate", time.Minute)
+	if a.timeout("locate") != time.Second*30 {
+		t.Fatal("deadline exceeded the maximum")
+	}
+}
+
+// testing the wait helper retries until the server accepts authentication
+func TestWaitForServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept() //first attempt is dropped while the server boots
+		if err != nil {
+			return
+		}
+		conn.Close()
+
+		conn, err = ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		head, _, err := readTestPacket(conn)
+		if err != nil {
+			return
+		}
+		writeTestPacket(conn, head.RequestID, CommandPacket, nil)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	port := ln.Addr().(*net.TCPAddr).Port
+	err = WaitForServer(ctx, "127.0.0.1", port, "password", time.Millisecond*10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//a wrong password is reported right away instead of polling until the context ends
+	host, authPort := startAuthTestServer(t, "secret")
+	err = WaitForServer(ctx, host, authPort, "wrong", time.Millisecond*10)
+	if !errors.Is(err, ErrAuthFailed) || ctx.Err() != nil {
+		t.Fatalf("expected an immediate authentication failure, got %v", err)
+	}
+}
+
+// testing packet type names, parsing, and invalid response types
+func TestPacketTypes(t *testing.T) {
+	if PacketType(AuthResponsePacket).String() != "AUTH_RESPONSE" || SourcePacketTypes.Request(CommandPacket) != "EXECCOMMAND" {
+		t.Fatal("unexpected packet type names")
+	}
+	var legacy int32 = CommandPacket //the constants are still usable as int32 values
+	if PacketType(legacy) != CommandPacket {
+		t.Fatal("unexpected legacy packet type")
+	}
+	pt, err := ParsePacketType("SERVERDATA_AUTH")
+	if err != nil || pt != AuthPacket {
+		t.Fatal("failed to parse packet type name")
+	}
+	if _, err := ParsePacketType("bogus"); !errors.Is(err, ErrInvalidPacketType) {
+		t.Fatal("expected unknown packet type name to fail")
+	}
+
+	_, err = roundTripPacket([]byte("body"), PacketType(7), ResetID)
+	if err == nil || !strings.Contains(err.Error(), "invalid source response packet type 7") {
+		t.Fatalf("expected a descriptive invalid type error, got %v", err)
+	}
+}
+
+// testing configuration snapshots and applying options before connecting
+func TestConfigAndApply(t *testing.T) {
+	tc := NewClient("testing", WithLabels(map[string]string{"env": "prod"}))
+	cfg := tc.Config()
+	cfg.Labels["env"] = "dev"
+	if tc.Labels()["env"] != "prod" {
+		t.Fatal("config snapshot shares labels with the client")
+	}
+
+	err := tc.Apply(WithPort(9876), WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := tc.Config(); cfg.Port != 9876 || !cfg.ReadOnly {
+		t.Fatal("applied options are missing from the config")
+	}
+
+	tc.connection, _ = net.Pipe()
+	defer tc.Close()
+	if tc.Apply(WithPort(1)) == nil {
+		t.Fatal("options were applied to a connected client")
+	}
+}
+
+// starts a local test server that accepts any password and echoes commands, the stop command closes the
+// connection after replying
+func startTestServer(t *testing.T) (string, int) {
+	return startAuthTestServer(t, "")
+}
+
+// starts a test server that only accepts the password, an empty password accepts any password
+func startAuthTestServer(t *testing.T, password string) (string, int) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				for {
+					head, body, err := readTestPacket(conn)
+					if err != nil {
+						return
+					}
+					if head.Type == AuthPacket {
+						id := head.RequestID
+						if password != "" && string(body) != password {
+							id = FailurePacket
+						}
+						writeTestPacket(conn, id, AuthResponsePacket, nil)
+						continue
+					}
+					writeTestPacket(conn, head.RequestID, ResponsePacket, body)
+					if string(body) == "stop" {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+// testing a coordinated restart reconnects the client after the supervisor runs
+func TestRestart(t *testing.T) {
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	restarted := false
+	sup := SupervisorFunc(func(ctx context.Context) error {
+		restarted = true
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	report, err := tc.Restart(ctx, sup, "password", time.Millisecond*10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restarted || report.StopResponse != "stop" || report.AvailableAt.IsZero() {
+		t.Fatalf("unexpected restart report %+v", report)
+	}
+
+	res, err := tc.Command("list")
+	if err != nil || res != "list" {
+		t.Fatal("client was not reconnected after the restart")
+	}
+
+	//the server drops the connection without replying to stop, stop is sent once, and the client reconnects
+	//through its own dialer since the address only resolves there
+	stops := 0
+	tc = NewClient("rcon.invalid", WithPort(port), WithAutoReconnect(time.Millisecond, time.Millisecond, 3),
+		WithDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+			conn, err := (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort(host, strconv.Itoa(port)))
+			if err != nil {
+				return nil, err
+			}
+			return &stopDropConn{Conn: conn, stops: &stops}, nil
+		}))
+	err = tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	_, err = tc.Restart(ctx, sup, "password", time.Millisecond*10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stops != 1 {
+		t.Fatalf("stop was sent %d times", stops)
+	}
+	res, err = tc.Command("list")
+	if err != nil || res != "list" {
+		t.Fatal("client was not reconnected after the restart")
+	}
+}
+
+// connection closed by the server as soon as the stop command is written
+type stopDropConn struct {
+	net.Conn
+	stops *int
+}
+
+func (c *stopDropConn) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte("stop")) {
+		*c.stops++
+		c.Conn.Close()
+		return len(p), nil
+	}
+	return c.Conn.Write(p)
+}
+
+// testing the bound connection is captured so a late cancellation never touches a cleared connection
+func TestBindAfterClose(t *testing.T) {
+	tc := NewClient("testing")
+	tc.connection, _ = net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	unbind := tc.bind(ctx)
+	unbind()
+	tc.Close()
+	cancel() //must not touch the cleared connection
+}
+
+// testing cancelling a command context interrupts a read that would otherwise block forever
+func TestCommandContextCancel(t *testing.T) {
+	serv, recv := net.Pipe()
+	defer serv.Close()
+	testingClient := NewClient("testing")
+	testingClient.connection = recv
+	defer testingClient.Close()
+
+	go readTestPacket(serv) //read the command but never reply
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*20, cancel)
+	_, err := testingClient.CommandContext(ctx, "list")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	_, err = testingClient.CommandContext(ctx, "list")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// testing execute chains are built and invalid targets are rejected
+func TestExecution(t *testing.T) {
+	tc := NewClient("testing")
+	cmd, err := tc.As("@a[team=red]").In("minecraft:the_nether").Positioned(1.5, 64, -3).Build("/say hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd != "execute as @a[team=red] in minecraft:the_nether positioned 1.5 64 -3 run say hi" {
+		t.Fatalf("unexpected execute command %q", cmd)
+	}
+
+	for _, target := range []string{"@q", "@a[team=red", "not a player"} {
+		if _, err := tc.At(target).Build("say hi"); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("invalid target %q was accepted", target)
+		}
+	}
+}
+
+// testing responses split by the server are joined by the default reassembly strategy
+func TestReassemblyAuto(t *testing.T) {
+	serv, recv := net.Pipe()
+	defer serv.Close()
+	testingClient := NewClient("testing")
+	testingClient.connection = recv
+
+	first := strings.Repeat("a", MaxFragmentSize)
+	go func() {
+		cmd, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte(first))
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte("tail"))
+	}()
+
+	res, err := testingClient.Command("help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != first+"tail" {
+		t.Fatalf("split response was not joined, got %d bytes", len(res))
+	}
+	testingClient.Close()
+
+	//fragments are measured before decoding, escaped text decodes to fewer bytes than the server sent
+	serv, recv = net.Pipe()
+	defer serv.Close()
+	testingClient = NewClient("testing", WithEncoding(EncodingEscaped))
+	testingClient.connection = recv
+
+	escaped := strings.Repeat(`\u00e9`, 682) + "aaaa"
+	go func() {
+		cmd, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte(escaped))
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte("tail"))
+	}()
+
+	res, err = testingClient.Command("help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != strings.Repeat("é", 682)+"aaaatail" {
+		t.Fatalf("escaped split response was not joined, got %d bytes", len(res))
+	}
+	testingClient.Close()
+
+	//characters split across the fragment boundary are decoded once the fragments are joined
+	serv, recv = net.Pipe()
+	defer serv.Close()
+	testingClient = NewClient("testing")
+	testingClient.connection = recv
+
+	split := strings.Repeat("a", MaxFragmentSize-1) + "é"
+	go func() {
+		cmd, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte(split[:MaxFragmentSize]))
+		writeTestPacket(serv, cmd.RequestID, ResponsePacket, []byte(split[MaxFragmentSize:]+"é"))
+	}()
+
+	res, err = testingClient.Command("help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != split+"é" {
+		t.Fatalf("split character was garbled, got %q", res[MaxFragmentSize-2:])
+	}
+	testingClient.Close()
+}
+
+// testing a command is retried on a new connection after the server drops the old one
+func TestAutoReconnect(t *testing.T) {
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port), WithAutoReconnect(time.Millisecond, 10*time.Millisecond, 3))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	_, err = tc.Command("stop") //the test server closes the connection after replying
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := tc.Command("list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "list" {
+		t.Fatalf("unexpected response %q", res)
+	}
+
+	//state-changing commands are not sent again but the client is reconnected for the next command
+	tc.Command("stop")
+	_, err = tc.Command("say hi")
+	if !isBroken(err) {
+		t.Fatalf("expected the broken connection to be reported, got %v", err)
+	}
+	res, err = tc.Command("say hi")
+	if err != nil || res != "say hi" {
+		t.Fatalf("expected the client to reconnect, got %q %v", res, err)
+	}
+
+	//without the option the broken connection is reported
+	tc = NewClient(host, WithPort(port))
+	err = tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	tc.Command("stop")
+	_, err = tc.Command("list")
+	if !isBroken(err) {
+		t.Fatalf("expected a broken connection error, got %v", err)
+	}
+}
+
+// testing the backoff grows and stays within the bounds
+func TestReconnectBackoff(t *testing.T) {
+	p := reconnectPolicy{min: 10 * time.Millisecond, max: 40 * time.Millisecond, attempts: 5}
+	for attempt, want := range []time.Duration{10, 20, 40, 40} {
+		want *= time.Millisecond
+		d := p.delay(attempt + 1)
+		if d < want/2 || d > want {
+			t.Fatalf("attempt %d: delay %v outside [%v, %v]", attempt+1, d, want/2, want)
+		}
+	}
+}
+
+// testing password candidates are tried in order and the accepted one is reported
+func TestPasswordCandidates(t *testing.T) {
+	host, port := startAuthTestServer(t, "new")
+
+	tc := NewClient(host, WithPort(port), WithPasswordCandidates(
+		PasswordFunc(func(ctx context.Context) (string, error) { return "", errors.New("secret store unavailable") }),
+		StaticPassword("old"),
+		StaticPassword("new"),
+	))
+	err := tc.Connect("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	report := tc.ConnectReport()
+	if report.Candidate != 2 || report.Attempts != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	tc = NewClient(host, WithPort(port), WithPasswordCandidates(StaticPassword("old")))
+	err = tc.Connect("new")
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed, got %v", err)
+	}
+	tc.Close()
+
+	tc = NewClient(host, WithPort(port))
+	err = tc.Connect("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	if tc.ConnectReport().Candidate != -1 {
+		t.Fatalf("unexpected report %+v", tc.ConnectReport())
+	}
+}
+
+// testing errors can be distinguished with errors.Is and errors.As
+func TestErrorTaxonomy(t *testing.T) {
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port))
+	_, err := tc.Command("list")
+	if !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("expected ErrNotConnected, got %v", err)
+	}
+
+	err = tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	if !errors.Is(tc.Apply(WithCap(5)), ErrAlreadyConnected) {
+		t.Fatal("expected ErrAlreadyConnected")
+	}
+
+	tc.Command("stop")
+	_, err = tc.Command("list")
+	if !errors.Is(err, ErrConnectionClosed) {
+		t.Fatalf("expected ErrConnectionClosed, got %v", err)
+	}
+
+	_, err = SourcePacketTypes.decodeResponse(7)
+	var pte *PacketTypeError
+	if !errors.Is(err, ErrInvalidPacketType) || !errors.As(err, &pte) || pte.Type != 7 {
+		t.Fatalf("expected a PacketTypeError, got %v", err)
+	}
+}
+
+// testing the full response carries the raw body and timing
+func TestCommandFull(t *testing.T) {
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	before := time.Now()
+	res, err := tc.CommandFull("seed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Body != "seed" || string(res.Raw) != "seed" || res.Type != ResponsePacket || res.Fragments != 1 {
+		t.Fatalf("unexpected response %+v", res)
+	}
+	if res.RequestID != ResetID+1 { //the auth packet used the first id
+		t.Fatalf("unexpected request id %d", res.RequestID)
+	}
+	if res.Sent.Before(before) || res.Latency <= 0 {
+		t.Fatalf("unexpected timing %v %v", res.Sent, res.Latency)
+	}
+	if res.Outcome != OutcomeSuccess {
+		t.Fatalf("unexpected outcome %s", res.Outcome)
+	}
+
+	res, err = tc.CommandFull("No player was found") //the test server echoes the command
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Outcome != OutcomePlayerNotFound {
+		t.Fatalf("unexpected outcome %s", res.Outcome)
+	}
+}
+
+// testing conformance mode cites the rule a response violates
+func TestConformance(t *testing.T) {
+	tests := []struct {
+		section string //expected section, empty for a conforming response
+		reply   func(conn net.Conn, id int32)
+	}{
+		{"", func(conn net.Conn, id int32) { writeTestPacket(conn, id, ResponsePacket, []byte("ok")) }},
+		{"Packet_ID", func(conn net.Conn, id int32) { writeTestPacket(conn, id+5, ResponsePacket, []byte("ok")) }},
+		{"Packet_Type", func(conn net.Conn, id int32) { writeTestPacket(conn, id, AuthResponsePacket, []byte("ok")) }},
+		{"Packet_Body", func(conn net.Conn, id int32) { writeTestPacket(conn, id, ResponsePacket, []byte("o\x00k")) }},
+		{"Empty_String", func(conn net.Conn, id int32) {
+			binary.Write(conn, binary.LittleEndian, headers{Size: 12, RequestID: id, Type: ResponsePacket})
+			conn.Write([]byte("ok\x00x"))
+		}},
+		{"Packet_Size", func(conn net.Conn, id int32) {
+			binary.Write(conn, binary.LittleEndian, headers{Size: 8, RequestID: id, Type: ResponsePacket})
+		}},
+	}
+
+	for _, test := range tests {
+		serv, recv := net.Pipe()
+		tc := NewClient("testing", WithConformance(), WithReassemblyStrategy(ReassemblyNone))
+		tc.connection = recv
+
+		go func() {
+			head, _, err := readTestPacket(serv)
+			if err != nil {
+				return
+			}
+			test.reply(serv, head.RequestID)
+		}()
+
+		_, err := tc.Command("list")
+		var ce *ConformanceError
+		switch {
+		case test.section == "" && err != nil:
+			t.Fatalf("unexpected error %v", err)
+		case test.section != "" && (!errors.Is(err, ErrNonConformant) || !errors.As(err, &ce) || ce.Section != test.section):
+			t.Fatalf("expected a %s violation, got %v", test.section, err)
+		}
+		tc.Close()
+		serv.Close()
+	}
+}
+
+// testing identical state-changing commands are suppressed within the window
+func TestDuplicateSuppression(t *testing.T) {
+	host, port := startTestServer(t)
+	confirmed := 0
+	tc := NewClient(host, WithPort(port), WithDuplicateSuppression(DuplicatePolicy{Window: time.Minute}))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	_, err = tc.Command("ban Steve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tc.Command("/BAN  steve")
+	if !errors.Is(err, ErrDuplicateCommand) {
+		t.Fatalf("expected ErrDuplicateCommand, got %v", err)
+	}
+	for i := 0; i < 2; i++ { //queries are never duplicates
+		_, err = tc.Command("list")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := &duplicateGuard{
+		policy: DuplicatePolicy{Window: time.Second, Confirm: func(cmd string, previous time.Time) bool {
+			confirmed++
+			return true
+		}},
+		sent: make(map[string]time.Time),
+	}
+	now := time.Now()
+	if !g.check("kick Alex", now) || confirmed != 0 {
+		t.Fatal("expected the first command to be sent")
+	}
+	g.record("kick Alex", now)
+	if !g.check("kick Alex", now) || confirmed != 1 {
+		t.Fatal("expected the duplicate to be confirmed")
+	}
+	if !g.check("kick Alex", now.Add(2*time.Second)) || confirmed != 1 {
+		t.Fatal("expected commands outside the window to be sent without confirmation")
+	}
+
+	//commands the server never received are not recorded so they can be retried
+	serv, recv := net.Pipe()
+	tc = NewClient("testing", WithDuplicateSuppression(DuplicatePolicy{Window: time.Minute}))
+	tc.connection = recv
+	go func() {
+		readTestPacket(serv)
+		serv.Close()
+	}()
+	_, err = tc.Command("ban Alex")
+	if err == nil {
+		t.Fatal("expected the command to fail")
+	}
+	if !tc.duplicates.check("ban Alex", time.Now()) {
+		t.Fatal("failed command was recorded as sent")
+	}
+}
+
+// testing Size headers are validated before the body is allocated
+func TestResponseSizeBounds(t *testing.T) {
+	tests := []struct {
+		size int32
+		want error
+	}{
+		{2, ErrInvalidPacketSize},
+		{-50, ErrInvalidPacketSize},
+		{1<<31 - 1, ErrResponseTooLarge},
+		{64 + PacketRequestSize + 1, ErrResponseTooLarge},
+	}
+
+	for _, test := range tests {
+		serv, recv := net.Pipe()
+		tc := NewClient("testing", WithMaxResponseSize(64), WithReassemblyStrategy(ReassemblyNone))
+		tc.connection = recv
+
+		go func() {
+			head, _, err := readTestPacket(serv)
+			if err != nil {
+				return
+			}
+			binary.Write(serv, binary.LittleEndian, headers{Size: test.size, RequestID: head.RequestID, Type: ResponsePacket})
+		}()
+
+		_, err := tc.Command("list")
+		if !errors.Is(err, test.want) {
+			t.Fatalf("size %d: expected %v, got %v", test.size, test.want, err)
+		}
+		tc.Close()
+		serv.Close()
+	}
+
+	//fragments are bounded together
+	serv, recv := net.Pipe()
+	tc := NewClient("testing", WithMaxResponseSize(6000))
+	tc.connection = recv
+	go func() {
+		head, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		writeTestPacket(serv, head.RequestID, ResponsePacket, bytes.Repeat([]byte{'a'}, MaxFragmentSize))
+		writeTestPacket(serv, head.RequestID, ResponsePacket, bytes.Repeat([]byte{'a'}, MaxFragmentSize))
+	}()
+	_, err := tc.Command("help")
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+	tc.Close()
+	serv.Close()
+}
+
+// testing slow commands are kept in a ring buffer and every command is counted in the histogram
+func TestSlowLog(t *testing.T) {
+	var sunk []string
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port), WithFingerprint("minecraft"), WithSlowLog(0, 2, func(c SlowCommand) {
+		sunk = append(sunk, c.Command)
+	}))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	for _, cmd := range []string{"list", "seed", "help"} {
+		_, err = tc.Command(cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	log := tc.SlowLog()
+	if len(log.Entries) != 2 || log.Entries[0].Command != "seed" || log.Entries[1].Command != "help" {
+		t.Fatalf("unexpected entries %+v", log.Entries)
+	}
+	if log.Entries[1].ResponseSize != 4 || log.Entries[1].Fragments != 1 || log.Entries[1].Fingerprint != "minecraft" {
+		t.Fatalf("unexpected entry %+v", log.Entries[1])
+	}
+	if len(sunk) != 3 {
+		t.Fatalf("expected every command to reach the sink, got %v", sunk)
+	}
+
+	total := 0
+	for _, b := range log.Histogram {
+		total += b.Count
+	}
+	if total != 3 {
+		t.Fatalf("expected 3 commands in the histogram, got %d", total)
+	}
+
+	l := &slowLog{threshold: time.Second, entries: make([]SlowCommand, 0, 1), counts: make([]int, len(slowLogBounds))}
+	l.record(SlowCommand{Command: "fast", Latency: time.Millisecond})
+	if len(l.snapshot().Entries) != 0 || l.counts[0] != 1 {
+		t.Fatal("expected fast commands to only be counted")
+	}
+}
+
+// testing strict correlation discards stale responses and reports streams that cannot be resynchronized
+func TestStrictCorrelation(t *testing.T) {
+	tests := []struct {
+		strict bool
+		stale  int    //stale packets sent ahead of the response
+		want   string //expected body, empty when an error is expected
+	}{
+		{false, 1, "stale"},
+		{true, 1, "fresh"},
+		{true, maxStalePackets + 1, ""},
+	}
+
+	for _, test := range tests {
+		serv, recv := net.Pipe()
+		opts := []Option{WithReassemblyStrategy(ReassemblyNone)}
+		if test.strict {
+			opts = append(opts, WithStrictCorrelation())
+		}
+		tc := NewClient("testing", opts...)
+		tc.connection = recv
+
+		go func() {
+			head, _, err := readTestPacket(serv)
+			if err != nil {
+				return
+			}
+			for i := 0; i < test.stale; i++ {
+				writeTestPacket(serv, head.RequestID+10, ResponsePacket, []byte("stale"))
+			}
+			writeTestPacket(serv, head.RequestID, ResponsePacket, []byte("fresh"))
+		}()
+
+		res, err := tc.Command("list")
+		switch {
+		case test.want == "" && !errors.Is(err, ErrRequestIDMismatch):
+			t.Fatalf("expected ErrRequestIDMismatch, got %v", err)
+		case test.want != "" && (err != nil || res != test.want):
+			t.Fatalf("expected %q, got %q %v", test.want, res, err)
+		}
+		tc.Close()
+		serv.Close()
+	}
+}
+
+// testing the failover client switches endpoints on failure and fails back to the primary
+func TestFailoverClient(t *testing.T) {
+	host, port := startTestServer(t)
+	down := map[string]bool{"primary": true}
+	//both endpoints reach the test server unless marked down
+	dialer := WithDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		name, _, _ := strings.Cut(address, ":")
+		if down[name] {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort(host, strconv.Itoa(port)))
+	})
+
+	fc := NewFailoverClient([]Endpoint{{"primary", port}, {"secondary", port}}, FailoverPolicy{FailureThreshold: 1, FailbackAfter: time.Nanosecond}, dialer)
+	err := fc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fc.Close()
+	if fc.Active().Address != "secondary" {
+		t.Fatalf("expected the secondary endpoint, got %v", fc.Active())
+	}
+
+	//the primary recovers and is probed before the next command
+	down["primary"] = false
+	_, err = fc.Command("list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fc.Active().Address != "primary" {
+		t.Fatalf("expected to fail back to the primary, got %v", fc.Active())
+	}
+
+	//the primary goes down and the command is retried on the secondary
+	down["primary"] = true
+	fc.Command("stop")
+	res, err := fc.Command("list")
+	if err != nil || res != "list" {
+		t.Fatalf("expected the command to be retried, got %q %v", res, err)
+	}
+	if fc.Active().Address != "secondary" {
+		t.Fatalf("expected the secondary endpoint, got %v", fc.Active())
+	}
+
+	//cancelled commands are not counted against the endpoint
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = fc.CommandContext(ctx, "list")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the command to be cancelled, got %v", err)
+	}
+	if fc.Active().Address != "secondary" || fc.failures != 0 {
+		t.Fatalf("cancelled command switched endpoints to %v", fc.Active())
+	}
+
+	//a zero interval uses the default and negative intervals never fail back
+	if NewFailoverClient(nil, FailoverPolicy{}).policy.FailbackAfter != DefaultFailbackAfter {
+		t.Fatal("zero failback interval did not use the default")
+	}
+	down["primary"] = false
+	fc.policy.FailbackAfter = -1
+	_, err = fc.Command("list")
+	if err != nil || fc.Active().Address != "secondary" {
+		t.Fatalf("expected to stay on the secondary endpoint, got %v %v", fc.Active(), err)
+	}
+}
+
+// testing the connection state follows failures and Reconnect repairs the client in place
+func TestStateAndReconnect(t *testing.T) {
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port))
+	if tc.State() != StateDisconnected || tc.IsConnected() {
+		t.Fatalf("unexpected state %v", tc.State())
+	}
+
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	if tc.State() != StateConnected || !tc.IsConnected() {
+		t.Fatalf("unexpected state %v", tc.State())
+	}
+
+	tc.Command("stop")
+	tc.Command("list")
+	if tc.State() != StateBroken || tc.IsConnected() {
+		t.Fatalf("unexpected state %v", tc.State())
+	}
+
+	err = tc.Reconnect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := tc.Command("list")
+	if err != nil || res != "list" || tc.State() != StateConnected {
+		t.Fatalf("unexpected result %q %v in state %v", res, err, tc.State())
+	}
+}
+
+// testing connections are made through the custom dialer
+func TestWithDialer(t *testing.T) {
+	host, port := startTestServer(t)
+	var dialed string
+	tc := NewClient("game.internal", WithPort(25575), WithDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = address
+		return (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort(host, strconv.Itoa(port)))
+	}))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	if dialed != "game.internal:25575" {
+		t.Fatalf("unexpected dial address %q", dialed)
+	}
+}
+
+// testing maintenance window schedules and enforcement
+func TestMaintenanceWindows(t *testing.T) {
+	w, err := ParseMaintenanceWindow("0 3 * * 0", 2*time.Hour, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sunday := time.Date(2024, 6, 2, 3, 30, 0, 0, time.UTC)
+	if !w.Open(sunday) || w.Open(sunday.Add(2*time.Hour)) || w.Open(sunday.Add(-time.Hour)) {
+		t.Fatal("unexpected window state")
+	}
+	next, ok := w.Next(sunday)
+	if !ok || !next.Equal(time.Date(2024, 6, 9, 3, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected next window %v", next)
+	}
+
+	//windows are evaluated in their own location
+	ny, err := time.LoadLocation("America/New_York")
+	if err == nil {
+		w, err = ParseMaintenanceWindow("30 22 1-7 * *", time.Hour, ny)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !w.Open(time.Date(2024, 6, 4, 2, 45, 0, 0, time.UTC)) { //22:45 EDT on June 3rd
+			t.Fatal("expected the window to be open")
+		}
+	}
+
+	for _, spec := range []string{"0 3 * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err = ParseMaintenanceWindow(spec, time.Hour, nil)
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("expected %q to be rejected", spec)
+		}
+	}
+
+	//a window that never opens rejects state-changing commands even when they are queued
+	closed, err := ParseMaintenanceWindow("0 0 31 2 *", time.Minute, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok = closed.Next(sunday)
+	if ok {
+		t.Fatal("expected the 31st of February to never open")
+	}
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port), WithMaintenanceWindows(MaintenancePolicy{Windows: []*MaintenanceWindow{closed}, Queue: true}))
+	err = tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	_, err = tc.Command("list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tc.Command("ban Steve")
+	if !errors.Is(err, ErrOutsideMaintenanceWindow) {
+		t.Fatalf("expected ErrOutsideMaintenanceWindow, got %v", err)
+	}
+}
+
+// tunnel dialing a fixed address, standing in for an ssh client
+type testTunnel struct {
+	target string
+	dialed []string
+}
+
+func (t *testTunnel) Dial(network, address string) (net.Conn, error) {
+	t.dialed = append(t.dialed, address)
+	return net.Dial(network, t.target)
+}
+
+// testing connections are opened through the tunnel
+func TestSSHTunnel(t *testing.T) {
+	host, port := startTestServer(t)
+	tunnel := &testTunnel{target: net.JoinHostPort(host, strconv.Itoa(port))}
+	tc := NewClient("127.0.0.1", WithPort(25575), WithSSHTunnel(tunnel))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	res, err := tc.Command("list")
+	if err != nil || res != "list" {
+		t.Fatalf("unexpected result %q %v", res, err)
+	}
+	if len(tunnel.dialed) != 1 || tunnel.dialed[0] != "127.0.0.1:25575" {
+		t.Fatalf("unexpected tunnel dials %v", tunnel.dialed)
+	}
+}
+
+// testing broadcasts are prefixed with the identity from the context
+func TestIdentityPrefix(t *testing.T) {
+	tests := map[string]string{
+		"say hello":                         "say [admin:alice] hello",
+		"/broadcast restart soon":           "/broadcast [admin:alice] restart soon",
+		`tellraw @a {"text":"hi"}`:          `tellraw @a ["[admin:alice] ",{"text":"hi"}]`,
+		`tellraw @a[team=red, tag=x] "hey"`: `tellraw @a[team=red, tag=x] ["[admin:alice] ","hey"]`,
+		"kick Steve":                        "kick Steve",
+	}
+	for cmd, want := range tests {
+		got := prefixIdentity(cmd, DefaultIdentityFormat, "admin:alice")
+		if got != want {
+			t.Fatalf("%q: expected %q, got %q", cmd, want, got)
+		}
+	}
+
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port), WithIdentityPrefix(""))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	res, err := tc.CommandContext(WithIdentity(context.Background(), "admin:alice"), "say hi")
+	if err != nil || res != "say [admin:alice] hi" {
+		t.Fatalf("unexpected result %q %v", res, err)
+	}
+	res, err = tc.Command("say hi")
+	if err != nil || res != "say hi" {
+		t.Fatalf("unexpected result %q %v", res, err)
+	}
+}
+
+// testing the file lock excludes other holders until it is released
+func TestFileLocker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.lock")
+	first := &FileLocker{Path: path, Interval: time.Millisecond}
+	second := &FileLocker{Path: path, Interval: time.Millisecond}
+
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	err = tc.Exclusive(context.Background(), first, func(c *Client) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := second.Lock(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the lock to be busy, got %v", err)
+		}
+
+		_, err = c.Command("save-off")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := second.Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+}
+
+// testing the debug bundle reports recent requests without the password
+func TestDebugBundle(t *testing.T) {
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port), WithFingerprint("source"))
+	err := tc.Connect("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	tc.Command("list")
+	tc.Command("say meet at spawn")
+	tc.Command("stop")
+	tc.Command("list") //fails, the server closed the connection
+
+	data, err := tc.DebugBundle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("hunter2")) {
+		t.Fatal("the bundle contains the password")
+	}
+	if bytes.Contains(data, []byte("meet at spawn")) {
+		t.Fatal("the bundle contains command arguments")
+	}
+
+	var bundle DebugBundle
+	err = json.Unmarshal(data, &bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Trace) != 5 || bundle.Trace[0].Type != "AUTH" || bundle.Trace[1].Command != "list" || bundle.Trace[2].Command != "say" {
+		t.Fatalf("unexpected trace %+v", bundle.Trace)
+	}
+	if len(bundle.Errors) != 1 || bundle.Quirks != "auth-junk-packet" || bundle.State != "broken" {
+		t.Fatalf("unexpected bundle %s", data)
+	}
+
+	tc = NewClient(host, WithPort(port), WithFullTrace())
+	err = tc.Connect("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	tc.Command("say meet at spawn")
+	trace, _ := tc.trace.snapshot()
+	if len(trace) != 2 || trace[1].Command != "say meet at spawn" {
+		t.Fatalf("expected the full command, got %+v", trace)
+	}
+}
+
+// transport counting dials, standing in for an alternative stream transport
+type countingTransport struct {
+	TCPTransport
+	dials int
+}
+
+func (t *countingTransport) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	t.dials++
+	return t.TCPTransport.Dial(ctx, network, address)
+}
+
+// testing connections are opened with the configured transport
+func TestWithTransport(t *testing.T) {
+	host, port := startTestServer(t)
+	transport := &countingTransport{}
+	tc := NewClient(host, WithPort(port), WithTransport(transport))
+	err := tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	res, err := tc.Command("list")
+	if err != nil || res != "list" || transport.dials != 1 {
+		t.Fatalf("unexpected result %q %v after %d dials", res, err, transport.dials)
+	}
+}
+
+// testing the protocol description matches the codec tables
+func TestDescribeProtocol(t *testing.T) {
+	doc := DescribeProtocol()
+	if len(doc.Fields) != 5 || doc.Fields[0].Name != "Size" || doc.MaxFragmentSize != MaxFragmentSize {
+		t.Fatalf("unexpected layout %+v", doc.Fields)
+	}
+	if len(doc.PacketTypes) != len(SourcePacketTypes.Requests)+len(SourcePacketTypes.Responses) {
+		t.Fatalf("unexpected packet types %+v", doc.PacketTypes)
+	}
+	for _, q := range doc.Quirks {
+		if q.Value == QuirkAuthJunkPacket && (len(q.Fingerprints) != 1 || q.Fingerprints[0] != "source") {
+			t.Fatalf("unexpected quirk %+v", q)
+		}
+	}
+}
+
+// testing empty passwords are sent unless required and rejections of them are explained
+func TestEmptyPassword(t *testing.T) {
+	host, port := startTestServer(t)
+	tc := NewClient(host, WithPort(port), WithRequirePassword())
+	err := tc.Connect("")
+	if !errors.Is(err, ErrMissingPassword) {
+		t.Fatalf("expected ErrMissingPassword, got %v", err)
+	}
+	err = tc.Connect("pass\x00word")
+	if !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("expected ErrInvalidPassword, got %v", err)
+	}
+	if tc.State() != StateDisconnected {
+		t.Fatal("invalid passwords should be rejected before dialing")
+	}
+
+	err = tc.Connect(EmptyPassword) //intentionally empty passwords pass the requirement
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.Close()
+
+	tc = NewClient(host, WithPort(port))
+	err = tc.Connect("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.Close()
+
+	host, port = startAuthTestServer(t, "secret")
+	tc = NewClient(host, WithPort(port))
+	err = tc.Connect("")
+	if !errors.Is(err, ErrAuthFailed) || !strings.Contains(err.Error(), "empty password") {
+		t.Fatalf("expected an empty password rejection, got %v", err)
+	}
+	tc.Close()
+	tc = NewClient(host, WithPort(port), WithRequirePassword())
+	err = tc.Connect(EmptyPassword)
+	if !errors.Is(err, ErrAuthFailed) || !strings.Contains(err.Error(), "empty password") {
+		t.Fatalf("expected an empty password rejection, got %v", err)
+	}
+	tc.Close()
+
+	tc = NewClient(host, WithPort(port), WithRequirePassword(), WithPasswordCandidates(StaticPassword(""), StaticPassword("secret")))
+	err = tc.Connect("")
+	if err != nil || tc.ConnectReport().Candidate != 1 || tc.ConnectReport().Attempts != 1 {
+		t.Fatalf("expected the second candidate to be accepted, got %v %+v", err, tc.ConnectReport())
+	}
+	tc.Close()
+}
+
+// testing the SRV record replaces the address and port when it exists
+func TestSRVLookup(t *testing.T) {
+	defer func(orig func(context.Context, string, string, string) (string, []*net.SRV, error)) { lookupSRV = orig }(lookupSRV)
+	lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		if service != "minecraft" || proto != "tcp" || name != "play.example.com" {
+			return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		}
+		return "", []*net.SRV{{Target: "mc1.example.com.", Port: 25575}}, nil
+	}
+
+	tc := NewClient("play.example.com", WithPort(1234), WithSRVLookup())
+	if addr := tc.dialAddress(context.Background()); addr != "mc1.example.com:25575" {
+		t.Fatalf("unexpected address %q", addr)
+	}
+	tc = NewClient("other.example.com", WithPort(1234), WithSRVLookup())
+	if addr := tc.dialAddress(context.Background()); addr != "other.example.com:1234" {
+		t.Fatalf("expected the configured address, got %q", addr)
+	}
+	tc = NewClient("play.example.com", WithPort(1234))
+	if addr := tc.dialAddress(context.Background()); addr != "play.example.com:1234" {
+		t.Fatalf("expected no lookup without the option, got %q", addr)
+	}
+}
+
+// testing each trim policy against response fixtures
+func TestTrimPolicies(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "trim.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []struct {
+		Name       string `json:"name"`
+		Payload    string `json:"payload"`
+		Padding    string `json:"padding"`
+		Nulls      string `json:"nulls"`
+		Whitespace string `json:"whitespace"`
+	}
+	err = json.Unmarshal(data, &fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range fixtures {
+		for policy, want := range map[TrimPolicy]string{
+			TrimPadding:    f.Padding,
+			TrimNulls:      f.Nulls,
+			TrimWhitespace: f.Whitespace,
+			TrimNone:       f.Payload,
+		} {
+			got := string(trimBody([]byte(f.Payload), policy, 0))
+			if got != want {
+				t.Fatalf("%s: policy %d: expected %q, got %q", f.Name, policy, want, got)
+			}
+		}
+	}
+
+	//the policy applies to responses read from the connection
+	serv, recv := net.Pipe()
+	tc := NewClient("testing", WithTrimPolicy(TrimNulls), WithReassemblyStrategy(ReassemblyNone))
+	tc.connection = recv
+	go func() {
+		head, _, err := readTestPacket(serv)
+		if err != nil {
+			return
+		}
+		binary.Write(serv, binary.LittleEndian, headers{Size: int32(PacketHeaderSize + len("Saved the game")), RequestID: head.RequestID, Type: ResponsePacket})
+		serv.Write([]byte("Saved the game"))
+	}()
+	res, err := tc.Command("save-all")
+	if err != nil || res != "Saved the game" {
+		t.Fatalf("unexpected result %q %v", res, err)
+	}
+	tc.Close()
+	serv.Close()
+}
+
+// testing the connection is made from the local address
+func TestWithLocalAddr(t *testing.T) {
+	host, port := startTestServer(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0") //reserve a free port for the source address
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	tc := NewClient(host, WithPort(port), WithLocalAddr(local))
+	err = tc.Connect("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	if tc.connection.LocalAddr().String() != local.String() {
+		t.Fatalf("expected the connection to use %v, got %v", local, tc.connection.LocalAddr())
+	}
+}
+
+// testing socket options are applied to tcp connections
+func TestSocketOptions(t *testing.T) {
+	host, port := startTestServer(t)
+	for _, opts := range [][]Option{
+		{WithNoDelay(false), WithKeepAlive(30 * time.Second)},
+		{WithNoDelay(true), WithKeepAlive(-1)},
+	} {
+		tc := NewClient(host, append(opts, WithPort(port))...)
+		err := tc.Connect("password")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tc.Command("list")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tc.Close()
+	}
+	if NewClient(host, WithNoDelay(false)).Config().NoDelay {
+		t.Fatal("expected Nagle's algorithm to be enabled")
+	}
+}
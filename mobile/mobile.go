@@ -0,0 +1,46 @@
+// Package mobile wraps the RCon client for gomobile bind so Android and iOS apps can embed it. Only types
+// supported by gomobile (strings, ints, errors, and pointers to exported structs) are used in the api
+package mobile
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+// remote console client safe for use from multiple app threads
+type Client struct {
+	mu     sync.Mutex
+	client *mcr.Client
+}
+
+// creates a client for the server address and port, a timeout of zero uses the default connection timeout
+func NewClient(address string, port int, timeoutMillis int) *Client {
+	opts := []mcr.Option{mcr.WithPort(port)}
+	if timeoutMillis > 0 {
+		opts = append(opts, mcr.WithTimeout(time.Duration(timeoutMillis)*time.Millisecond))
+	}
+	return &Client{client: mcr.NewClient(address, opts...)}
+}
+
+// connects to the server and authenticates the client
+func (c *Client) Connect(password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client.Connect(password)
+}
+
+// sends a command to the server and returns the server response
+func (c *Client) Command(cmd string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client.Command(cmd)
+}
+
+// closes the server connection
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client.Close()
+}
@@ -0,0 +1,18 @@
+package mobile
+
+import (
+	"testing"
+)
+
+// testing commands fail before the client connects
+func TestCommandBeforeConnect(t *testing.T) {
+	c := NewClient("testing", 9876, 500)
+	_, err := c.Command("list")
+	if err == nil {
+		t.Fatal("expected command to fail before connecting")
+	}
+	err = c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,176 @@
+package mcr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command syntax used for moderation actions
+type ModerationSyntax int
+
+const (
+	SyntaxVanilla    ModerationSyntax = iota //kick, ban, and tell, timed mutes and bans are unsupported
+	SyntaxEssentials                         //EssentialsX mute and tempban in addition to the vanilla commands
+)
+
+// outcome of a moderation action
+type ModerationResult struct {
+	Command  string  //command sent to the server
+	Response string  //server response
+	Outcome  Outcome //classified server response
+}
+
+// returns true if the server reported success
+func (r ModerationResult) OK() bool {
+	return r.Outcome == OutcomeSuccess
+}
+
+// moderation actions translated to the command syntax of the server, permission plugins such as LuckPerms
+// do not change the syntax so only the moderation plugin matters
+type Moderation struct {
+	client *Client
+	syntax ModerationSyntax
+}
+
+// returns moderation actions using the syntax
+func (c *Client) Moderation(syntax ModerationSyntax) *Moderation {
+	return &Moderation{client: c, syntax: syntax}
+}
+
+// detects the moderation plugin from the plugins command, servers without plugin support use the vanilla
+// syntax
+func (c *Client) DetectModeration(ctx context.Context) (*Moderation, error) {
+	res, err := c.CommandContext(ctx, "plugins")
+	if err != nil {
+		return nil, err
+	}
+	syntax := SyntaxVanilla
+	if Classify(res) == OutcomeSuccess && strings.Contains(formatPattern.ReplaceAllString(res, ""), "Essentials") {
+		syntax = SyntaxEssentials
+	}
+	return c.Moderation(syntax), nil
+}
+
+// returns the syntax the actions are sent with
+func (m *Moderation) Syntax() ModerationSyntax {
+	return m.syntax
+}
+
+// sends the player a private warning message
+func (m *Moderation) Warn(ctx context.Context, player, reason string) (ModerationResult, error) {
+	if reason == "" {
+		return ModerationResult{}, fmt.Errorf("%w: a warning requires a reason", ErrInvalidArgument)
+	}
+	return m.run(ctx, player, reason, "tell %s Warning: %s")
+}
+
+// disconnects the player
+func (m *Moderation) Kick(ctx context.Context, player, reason string) (ModerationResult, error) {
+	return m.run(ctx, player, reason, "kick %s %s")
+}
+
+// prevents the player from chatting for the duration, zero mutes until unmuted
+func (m *Moderation) Mute(ctx context.Context, player string, duration time.Duration, reason string) (ModerationResult, error) {
+	if m.syntax != SyntaxEssentials {
+		return ModerationResult{}, fmt.Errorf("%w: mute", ErrUnsupportedAction)
+	}
+	if duration > 0 {
+		return m.run(ctx, player, reason, "mute %s "+FormatDuration(duration)+" %s")
+	}
+	return m.run(ctx, player, reason, "mute %s %s")
+}
+
+// bans the player permanently
+func (m *Moderation) Ban(ctx context.Context, player, reason string) (ModerationResult, error) {
+	return m.run(ctx, player, reason, "ban %s %s")
+}
+
+// bans the player for the duration, permanent bans are sent with Ban
+func (m *Moderation) TempBan(ctx context.Context, player string, duration time.Duration, reason string) (ModerationResult, error) {
+	if duration <= 0 {
+		return ModerationResult{}, fmt.Errorf("%w: temporary bans require a positive duration", ErrInvalidArgument)
+	}
+	if m.syntax != SyntaxEssentials {
+		return ModerationResult{}, fmt.Errorf("%w: temporary ban", ErrUnsupportedAction)
+	}
+	return m.run(ctx, player, reason, "tempban %s "+FormatDuration(duration)+" %s")
+}
+
+// validates the arguments, sends the command, and classifies the response
+func (m *Moderation) run(ctx context.Context, player, reason, format string) (ModerationResult, error) {
+	if !playerPattern.MatchString(player) {
+		return ModerationResult{}, fmt.Errorf("%w: invalid player name %q", ErrInvalidArgument, player)
+	}
+	if strings.ContainsAny(reason, "\r\n") {
+		return ModerationResult{}, fmt.Errorf("%w: the reason cannot contain line breaks", ErrInvalidArgument)
+	}
+
+	cmd := strings.TrimSpace(fmt.Sprintf(format, player, reason))
+	res, err := m.client.CommandContext(ctx, cmd)
+	if err != nil {
+		return ModerationResult{Command: cmd}, err
+	}
+	return ModerationResult{Command: cmd, Response: res, Outcome: Classify(res)}, nil
+}
+
+// units accepted by ParseDuration, largest first
+var durationUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"w", time.Hour * 24 * 7},
+	{"d", time.Hour * 24},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// parses moderation durations such as "30m", "1d12h", or "2w" as accepted by moderation plugins
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("%w: empty duration", ErrInvalidArgument)
+	}
+	var total time.Duration
+	rest := strings.ToLower(s)
+	for rest != "" {
+		i := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, fmt.Errorf("%w: invalid duration %q", ErrInvalidArgument, s)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid duration %q", ErrInvalidArgument, s)
+		}
+		unit := time.Duration(0)
+		for _, u := range durationUnits {
+			if strings.HasPrefix(rest[i:], u.suffix) {
+				unit = u.unit
+				rest = rest[i+len(u.suffix):]
+				break
+			}
+		}
+		if unit == 0 {
+			return 0, fmt.Errorf("%w: invalid duration %q", ErrInvalidArgument, s)
+		}
+		total += time.Duration(n) * unit
+	}
+	return total, nil
+}
+
+// formats a duration in the syntax accepted by ParseDuration and moderation plugins, rounded down to seconds
+func FormatDuration(d time.Duration) string {
+	var sb strings.Builder
+	for _, u := range durationUnits {
+		if n := d / u.unit; n > 0 {
+			sb.WriteString(strconv.FormatInt(int64(n), 10) + u.suffix)
+			d -= n * u.unit
+		}
+	}
+	if sb.Len() == 0 {
+		return "0s"
+	}
+	return sb.String()
+}
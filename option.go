@@ -1,6 +1,8 @@
 package mcr
 
 import (
+	"context"
+	"net"
 	"time"
 )
 
@@ -27,3 +29,162 @@ func WithCap(c int32) Option {
 		cn.cap = c
 	}
 }
+
+// latency profile used to tune socket options and timeouts together for a workload
+type LatencyProfile int
+
+const (
+	LatencyInteractive LatencyProfile = iota + 1 //low latency for repl-style use
+	LatencyBulk                                  //throughput for mass scripting
+)
+
+// option to tune the client for the expected workload without setting each knob. LatencyInteractive disables
+// Nagle's algorithm and uses short read timeouts, LatencyBulk enables Nagle's algorithm so bursts of commands
+// are coalesced and allows slower responses
+func WithLatencyProfile(profile LatencyProfile) Option {
+	return func(cn *Client) {
+		switch profile {
+		case LatencyInteractive:
+			cn.noDelay = true
+			cn.readTimeout = time.Second * 5
+		case LatencyBulk:
+			cn.noDelay = false
+			cn.readTimeout = time.Second * 30
+		}
+	}
+}
+
+// option to identify the server software so known quirks are applied automatically, see LookupQuirks
+func WithFingerprint(fingerprint string) Option {
+	return func(cn *Client) {
+		cn.fingerprint = fingerprint
+	}
+}
+
+// option to override the quirks applied to the connection regardless of the server fingerprint
+func WithQuirks(quirks ...Quirk) Option {
+	return func(cn *Client) {
+		cn.quirks = 0
+		for _, q := range quirks {
+			cn.quirks |= q
+		}
+		cn.quirksSet = true
+	}
+}
+
+// option to override the encoding used for response bodies, by default the encoding is detected from the
+// response bytes
+func WithEncoding(enc Encoding) Option {
+	return func(cn *Client) {
+		cn.encoding = enc
+	}
+}
+
+// option to read responses split across multiple packets using the supplied strategy. ReassemblyAuto is the
+// default and follows fragments that fill the maximum fragment size. ReassemblySentinel is the most reliable
+// but some servers mishandle the sentinel packet, ReassemblyTimeout works with any server at the cost of
+// waiting for the fragment timeout after every command
+func WithReassemblyStrategy(strategy ReassemblyStrategy) Option {
+	return func(cn *Client) {
+		cn.reassembly = strategy
+	}
+}
+
+// option to allow for custom quiet periods when waiting for additional response fragments
+func WithFragmentTimeout(timeout time.Duration) Option {
+	return func(cn *Client) {
+		cn.fragmentTimeout = timeout
+	}
+}
+
+// option to allow for custom bounds when discarding stale packets after a failed request
+func WithDrainLimits(timeout time.Duration, bytes int) Option {
+	return func(cn *Client) {
+		cn.drainTimeout = timeout
+		cn.drainLimit = bytes
+	}
+}
+
+// option to bound the body bytes accepted for a single response including every fragment, responses
+// announcing a larger size are rejected with ErrResponseTooLarge before the body is allocated. Values below
+// one restore DefaultMaxResponseSize
+func WithMaxResponseSize(bytes int) Option {
+	return func(cn *Client) {
+		if bytes < 1 {
+			bytes = DefaultMaxResponseSize
+		}
+		cn.maxResponseSize = bytes
+	}
+}
+
+// option to route connections through a custom dialer such as a vpn interface, proxy library, or test
+// harness, the dialer receives the context passed to ConnectContext bounded by the client timeout
+func WithDialer(dial func(ctx context.Context, network, address string) (net.Conn, error)) Option {
+	return func(cn *Client) {
+		cn.transport = TransportFunc(dial)
+	}
+}
+
+// option to toggle Nagle's algorithm on tcp connections, disabling it (the default) sends each command
+// immediately while enabling it batches small writes during bulk scripting
+func WithNoDelay(noDelay bool) Option {
+	return func(cn *Client) {
+		cn.noDelay = noDelay
+	}
+}
+
+// option to send tcp keepalive probes every period so NAT gateways and firewalls do not silently drop idle
+// connections, a negative period disables keepalives and zero keeps the Go default of 15 seconds
+func WithKeepAlive(period time.Duration) Option {
+	return func(cn *Client) {
+		cn.keepAlive = period
+	}
+}
+
+// option to choose the source address of the outbound connection on multi-homed hosts, a *net.TCPAddr with a
+// zero port picks the interface only. The address is ignored by custom transports
+func WithLocalAddr(addr net.Addr) Option {
+	return func(cn *Client) {
+		cn.localAddr = addr
+	}
+}
+
+// option to attach labels (env=prod, region=eu) identifying the connection in fleet observability data,
+// labels are merged when the option is supplied more than once
+func WithLabels(labels map[string]string) Option {
+	return func(cn *Client) {
+		if cn.labels == nil {
+			cn.labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			cn.labels[k] = v
+		}
+	}
+}
+
+// option to require approval before sending commands matching the policy patterns, see DangerousCommands
+func WithConfirmation(policy ConfirmationPolicy) Option {
+	return func(cn *Client) {
+		cn.confirmation = &policy
+	}
+}
+
+// option to restrict the client to query commands (list, seed, data get, tps, help, banlist, whitelist list)
+// so it can safely be handed to monitoring systems
+func WithReadOnly() Option {
+	return func(cn *Client) {
+		cn.readOnly = true
+	}
+}
+
+// option to learn per-verb response times and set each command's read deadline to twice the slowest recent
+// response for its verb, bounded by min and max. Timed out commands extend the deadline for their verb
+func WithAdaptiveTimeouts(min, max time.Duration) Option {
+	return func(cn *Client) {
+		cn.adaptive = &adaptiveTimeouts{
+			min:   min,
+			max:   max,
+			verbs: make(map[string][]time.Duration),
+		}
+	}
+}
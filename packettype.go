@@ -0,0 +1,97 @@
+package mcr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// remote console packet type, the packet type constants are untyped so they can be used as a PacketType or
+// an int32
+type PacketType int32
+
+// names of the packet types a protocol uses in each direction
+type PacketTypeTable struct {
+	Protocol  string
+	Requests  map[PacketType]string //types sent by the client
+	Responses map[PacketType]string //types sent by the server
+}
+
+// packet types of the Source RCon protocol used by Minecraft
+var SourcePacketTypes = PacketTypeTable{
+	Protocol: "source",
+	Requests: map[PacketType]string{
+		AuthPacket:     "AUTH",
+		CommandPacket:  "EXECCOMMAND",
+		ResponsePacket: "RESPONSE_VALUE", //only sent by clients as a multi-packet sentinel
+	},
+	Responses: map[PacketType]string{
+		ResponsePacket:     "RESPONSE_VALUE",
+		AuthResponsePacket: "AUTH_RESPONSE",
+	},
+}
+
+// returns the Source RCon name of the packet type. Response names are preferred since logged packets are
+// usually responses, use SourcePacketTypes.Request for the request name
+func (t PacketType) String() string {
+	if name, ok := SourcePacketTypes.Responses[t]; ok {
+		return name
+	}
+	if name, ok := SourcePacketTypes.Requests[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("PacketType(%d)", int32(t))
+}
+
+// returns the name of a request packet type
+func (tbl PacketTypeTable) Request(t PacketType) string {
+	if name, ok := tbl.Requests[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("PacketType(%d)", int32(t))
+}
+
+// returns the name of a response packet type
+func (tbl PacketTypeTable) Response(t PacketType) string {
+	if name, ok := tbl.Responses[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("PacketType(%d)", int32(t))
+}
+
+// returns the packet type for a name from either direction, the SERVERDATA_ prefix is optional and names are
+// case-insensitive
+func (tbl PacketTypeTable) Parse(name string) (PacketType, error) {
+	name = strings.TrimPrefix(strings.ToUpper(name), "SERVERDATA_")
+	for _, names := range []map[PacketType]string{tbl.Requests, tbl.Responses} {
+		for t, n := range names {
+			if n == name {
+				return t, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown %s packet type name %q", ErrInvalidPacketType, tbl.Protocol, name)
+}
+
+// returns the Source RCon packet type for a name
+func ParsePacketType(name string) (PacketType, error) {
+	return SourcePacketTypes.Parse(name)
+}
+
+// validates a packet type received from the server
+func (tbl PacketTypeTable) decodeResponse(t PacketType) (PacketType, error) {
+	if _, ok := tbl.Responses[t]; !ok {
+		return t, &PacketTypeError{Protocol: tbl.Protocol, Type: t, Expected: tbl.responseNames()}
+	}
+	return t, nil
+}
+
+// lists the response packet types for error messages
+func (tbl PacketTypeTable) responseNames() string {
+	names := make([]string, 0, len(tbl.Responses))
+	for t, n := range tbl.Responses {
+		names = append(names, fmt.Sprintf("%s (%d)", n, int32(t)))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
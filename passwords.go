@@ -0,0 +1,102 @@
+package mcr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// pass to Connect to send an intentionally empty password when WithRequirePassword is set. The value holds a
+// null byte so it cannot collide with a real password, RCon bodies are null terminated
+const EmptyPassword = "\x00"
+
+// option to reject empty passwords with ErrMissingPassword instead of sending them, guarding against a
+// password that was never configured, such as an unset environment variable. Use EmptyPassword for servers
+// that accept an empty password
+func WithRequirePassword() Option {
+	return func(cn *Client) {
+		cn.requirePassword = true
+	}
+}
+
+// validates the password and returns the body sent to the server
+func (c *Client) authBody(password string) ([]byte, error) {
+	switch {
+	case password == EmptyPassword:
+		return []byte{}, nil
+	case password == "" && c.requirePassword:
+		return nil, ErrMissingPassword
+	case strings.ContainsRune(password, 0):
+		return nil, fmt.Errorf("%w: passwords cannot contain null bytes", ErrInvalidPassword)
+	}
+	return []byte(password), nil
+}
+
+// supplies a password when the client connects, implementations can read from files, environment variables,
+// or secret managers so rotated credentials are picked up without rebuilding the client
+type PasswordProvider interface {
+	Password(ctx context.Context) (string, error)
+}
+
+// adapter allowing ordinary functions to be used as a PasswordProvider
+type PasswordFunc func(ctx context.Context) (string, error)
+
+func (f PasswordFunc) Password(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// fixed password usable as a PasswordProvider
+type StaticPassword string
+
+func (p StaticPassword) Password(ctx context.Context) (string, error) {
+	return string(p), nil
+}
+
+// outcome of the last successful connection
+type ConnectReport struct {
+	Candidate int //index of the accepted password candidate, -1 when the password argument was used
+	Attempts  int //number of passwords sent to the server
+}
+
+// option to try several passwords in order when connecting, useful mid-rotation when either the old or new
+// password may be valid. The password passed to Connect is ignored while candidates are set, the next
+// candidate is only tried when the server rejects the previous one or its provider fails
+func WithPasswordCandidates(candidates ...PasswordProvider) Option {
+	return func(cn *Client) {
+		cn.candidates = candidates
+	}
+}
+
+// returns the report of the last successful connection
+func (c *Client) ConnectReport() ConnectReport {
+	return c.report
+}
+
+// authenticates with each candidate until one is accepted, network failures end the attempt immediately
+func (c *Client) connectCandidates(ctx context.Context) error {
+	err := fmt.Errorf("%w: no password candidates", ErrMissingPassword)
+	attempts := 0
+	for i, candidate := range c.candidates {
+		password, perr := candidate.Password(ctx)
+		if perr == nil {
+			_, perr = c.authBody(password)
+		}
+		if perr != nil {
+			err = fmt.Errorf("password candidate %d: %w", i, perr)
+			continue
+		}
+
+		attempts++
+		err = c.connect(ctx, password)
+		if err == nil {
+			c.report = ConnectReport{Candidate: i, Attempts: attempts}
+			return nil
+		}
+		if !errors.Is(err, ErrAuthFailed) {
+			return err
+		}
+		c.disconnect() //servers may drop the connection after a failed attempt, the session continues
+	}
+	return err
+}
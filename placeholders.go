@@ -0,0 +1,98 @@
+package mcr
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	//matches "There are 2 of a max of 20 players online: a, b" and the older "There are 2/20 players online:a, b"
+	listPattern = regexp.MustCompile(`There are (\d+)(?: of a max of |/)(\d+) players online:(.*)`)
+	//matches the first value of "TPS from last 1m, 5m, 15m: 20.0, 20.0, 20.0"
+	tpsPattern = regexp.MustCompile(`TPS from last [^:]*:\s*\*?([\d.]+)`)
+	//matches minecraft formatting codes
+	formatPattern = regexp.MustCompile(`§.`)
+)
+
+// expands placeholders in a command template by querying the server, only the placeholders present in the
+// template are queried. Supported placeholders are {online_count}, {max_players}, {random_player}, and {tps},
+// unknown placeholders are left untouched. {tps} requires a server that implements the tps command
+func (c *Client) Resolve(template string) (string, error) {
+	return c.ResolveContext(context.Background(), template)
+}
+
+// expands placeholders like Resolve, the context interrupts the queries sent to the server
+func (c *Client) ResolveContext(ctx context.Context, template string) (string, error) {
+	if strings.Contains(template, "{online_count}") || strings.Contains(template, "{max_players}") ||
+		strings.Contains(template, "{random_player}") {
+		online, max, names, err := c.listPlayers(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		template = strings.ReplaceAll(template, "{online_count}", strconv.Itoa(online))
+		template = strings.ReplaceAll(template, "{max_players}", strconv.Itoa(max))
+		if strings.Contains(template, "{random_player}") {
+			if len(names) == 0 {
+				return "", fmt.Errorf("%w: cannot resolve {random_player}", ErrNoPlayersOnline)
+			}
+			template = strings.ReplaceAll(template, "{random_player}", names[rand.Intn(len(names))])
+		}
+	}
+
+	if strings.Contains(template, "{tps}") {
+		tps, err := c.tps(ctx)
+		if err != nil {
+			return "", err
+		}
+		template = strings.ReplaceAll(template, "{tps}", tps)
+	}
+
+	return template, nil
+}
+
+// runs the list command and parses the online count, player cap, and player names
+func (c *Client) listPlayers(ctx context.Context) (int, int, []string, error) {
+	res, err := c.CommandContext(ctx, "list")
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return parseList(res)
+}
+
+// parses the response of the list command
+func parseList(res string) (int, int, []string, error) {
+	m := listPattern.FindStringSubmatch(formatPattern.ReplaceAllString(res, ""))
+	if m == nil {
+		return 0, 0, nil, fmt.Errorf("%w: list", ErrUnrecognizedResponse)
+	}
+
+	online, _ := strconv.Atoi(m[1])
+	max, _ := strconv.Atoi(m[2])
+	var names []string
+	for _, name := range strings.Split(m[3], ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return online, max, names, nil
+}
+
+// runs the tps command and returns the tps over the last minute
+func (c *Client) tps(ctx context.Context) (string, error) {
+	res, err := c.CommandContext(ctx, "tps")
+	if err != nil {
+		return "", err
+	}
+
+	m := tpsPattern.FindStringSubmatch(formatPattern.ReplaceAllString(res, ""))
+	if m == nil {
+		return "", fmt.Errorf("%w: tps", ErrUnrecognizedResponse)
+	}
+	return m[1], nil
+}
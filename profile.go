@@ -0,0 +1,45 @@
+package mcr
+
+import (
+	"time"
+)
+
+// bundled settings for a game whose RCon implementation deviates from the Source standard, see WithProfile.
+// Profiles have no packet type overrides since every profiled game uses the Source packet type values, the
+// extra packets Squad pushes are skipped by QuirkServerPush
+type Profile struct {
+	Name       string             //fingerprint of the server software, see LookupQuirks
+	Quirks     Quirk              //deviations applied while parsing packets
+	Reassembly ReassemblyStrategy //how responses split across packets are read
+	Trim       TrimPolicy         //how the bytes following the response body are removed
+	KeepAlive  time.Duration      //tcp keepalive probe period, zero keeps the client setting, no RCon packets are sent
+}
+
+// profiles for games with known deviations, the zero value of each field matches the vanilla Minecraft server
+var (
+	ProfileMinecraft = Profile{Name: "minecraft"}
+	//Palworld does not mirror the sentinel packet, TrimNulls keeps bodies intact with or without the padding
+	ProfilePalworld = Profile{Name: "palworld", Reassembly: ReassemblyNone, Trim: TrimNulls}
+	//ARK sends each response in a single packet, frequent tcp keepalive probes stop NAT gateways and firewalls
+	//dropping idle connections
+	ProfileARK = Profile{Name: "ark", Reassembly: ReassemblyNone, Trim: TrimNulls, KeepAlive: time.Second * 10}
+	//Factorio follows the Source standard including mirroring the sentinel packet
+	ProfileFactorio = Profile{Name: "factorio", Reassembly: ReassemblySentinel}
+	//Squad pushes chat packets between responses and mirrors the sentinel packet
+	ProfileSquad = Profile{Name: "squad", Quirks: QuirkServerPush, Reassembly: ReassemblySentinel}
+)
+
+// option to apply the settings known to work with a game so its quirks do not have to be discovered by hand,
+// options supplied after the profile override its settings
+func WithProfile(profile Profile) Option {
+	return func(cn *Client) {
+		cn.fingerprint = profile.Name
+		cn.quirks = profile.Quirks
+		cn.quirksSet = true
+		cn.reassembly = profile.Reassembly
+		cn.trim = profile.Trim
+		if profile.KeepAlive != 0 {
+			cn.keepAlive = profile.KeepAlive
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package mcr
+
+import (
+	"sort"
+	"strconv"
+)
+
+// field of the packet layout
+type FieldDoc struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Size        string `json:"size"` //bytes, variable fields describe their bounds
+	Description string `json:"description"`
+}
+
+// packet type in one direction
+type PacketTypeDoc struct {
+	Name      string `json:"name"`
+	Value     int32  `json:"value"`
+	Direction string `json:"direction"` //request or response
+}
+
+// quirk and the fingerprints that enable it
+type QuirkDoc struct {
+	Name         string   `json:"name"`
+	Value        Quirk    `json:"value"`
+	Description  string   `json:"description"`
+	Fingerprints []string `json:"fingerprints"`
+}
+
+// machine-readable description of the wire format implemented by the client, generated from the same
+// constants and tables the codec uses so documentation cannot drift from the code
+type ProtocolDoc struct {
+	Protocol        string          `json:"protocol"`
+	ByteOrder       string          `json:"byte_order"`
+	Fields          []FieldDoc      `json:"fields"`
+	PacketTypes     []PacketTypeDoc `json:"packet_types"`
+	Quirks          []QuirkDoc      `json:"quirks"`
+	MaxFragmentSize int             `json:"max_fragment_size"`
+	FailureID       int32           `json:"failure_id"`
+}
+
+// describes the packet layout, packet types, and quirks of the protocol, see cmd/mcr-protodoc for a renderer
+func DescribeProtocol() ProtocolDoc {
+	doc := ProtocolDoc{
+		Protocol:  SourcePacketTypes.Protocol,
+		ByteOrder: "little-endian",
+		Fields: []FieldDoc{
+			{"Size", "int32", "4", "length of the rest of the packet, at least " + strconv.Itoa(PacketRequestSize)},
+			{"RequestID", "int32", "4", "client chosen id echoed in the response, " + strconv.Itoa(int(FailurePacket)) + " when authentication fails"},
+			{"Type", "int32", "4", "packet type, see packet types"},
+			{"Body", "ascii string", "0-" + strconv.Itoa(MaxFragmentSize) + " per response packet", "command or response text, longer responses are split across packets"},
+			{"Padding", "bytes", strconv.Itoa(PacketPaddingSize), "null terminator of the body followed by an empty string"},
+		},
+		MaxFragmentSize: MaxFragmentSize,
+		FailureID:       FailurePacket,
+	}
+
+	for direction, names := range map[string]map[PacketType]string{"request": SourcePacketTypes.Requests, "response": SourcePacketTypes.Responses} {
+		for t, name := range names {
+			doc.PacketTypes = append(doc.PacketTypes, PacketTypeDoc{Name: "SERVERDATA_" + name, Value: int32(t), Direction: direction})
+		}
+	}
+	sort.Slice(doc.PacketTypes, func(i, j int) bool {
+		a, b := doc.PacketTypes[i], doc.PacketTypes[j]
+		if a.Direction != b.Direction {
+			return a.Direction < b.Direction
+		}
+		return a.Value < b.Value
+	})
+
+	for _, q := range quirkNames {
+		fingerprints := []string{}
+		for fp, quirks := range quirkRegistry {
+			if quirks.Has(q.quirk) {
+				fingerprints = append(fingerprints, fp)
+			}
+		}
+		sort.Strings(fingerprints)
+		doc.Quirks = append(doc.Quirks, QuirkDoc{Name: q.name, Value: q.quirk, Description: q.description, Fingerprints: fingerprints})
+	}
+	return doc
+}
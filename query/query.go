@@ -0,0 +1,319 @@
+// Package query implements the Minecraft Query protocol (GameSpy4 over UDP) for reading server status, player
+// lists, and plugins without RCon credentials. The server must set enable-query=true in server.properties
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jake-young-dev/mcr/internal/ctxerr"
+)
+
+const (
+	//default values
+	DefaultPort       = 25565
+	DefaultTimeout    = time.Second * 5
+	DefaultRetransmit = time.Second
+
+	//packet types
+	typeHandshake = 9
+	typeStat      = 0
+)
+
+var (
+	//prefix of every request packet
+	magic = []byte{0xfe, 0xfd}
+	//padding between the full stat header and the key value section
+	fullStatPadding = []byte("splitnum\x00\x80\x00")
+	//padding between the key value section and the player list
+	playerPadding = []byte("\x01player_\x00\x00")
+)
+
+// returned when the server reply cannot be parsed
+var ErrMalformedResponse = errors.New("malformed query response")
+
+// status returned by a basic stat request
+type BasicStat struct {
+	MOTD       string
+	GameType   string
+	Map        string
+	NumPlayers int
+	MaxPlayers int
+	HostPort   int
+	HostIP     string
+}
+
+// status returned by a full stat request
+type FullStat struct {
+	BasicStat
+	GameID  string
+	Version string
+	Plugins string            //server software and plugins, empty on vanilla servers
+	Values  map[string]string //every key value pair sent by the server
+	Players []string
+}
+
+// query protocol client, each request uses a new handshake so the client is safe for concurrent use
+type Client struct {
+	address    string
+	port       int
+	timeout    time.Duration
+	retransmit time.Duration
+}
+
+// client option func skeleton
+type Option func(c *Client)
+
+// option to allow for custom port values
+func WithPort(port int) Option {
+	return func(c *Client) {
+		c.port = port
+	}
+}
+
+// option to allow for custom timeouts, the timeout bounds each request including the handshake
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// option to resend unanswered packets every interval until the timeout passes, since udp packets can be lost
+// in transit. Each request carries the session id so late replies to earlier attempts and duplicated packets
+// are matched or discarded. Values below one disable retransmission
+func WithRetransmit(interval time.Duration) Option {
+	return func(c *Client) {
+		c.retransmit = interval
+	}
+}
+
+// creates a new query client configured with the supplied options
+func NewClient(addr string, opts ...Option) *Client {
+	c := &Client{
+		address:    addr,
+		port:       DefaultPort,
+		timeout:    DefaultTimeout,
+		retransmit: DefaultRetransmit,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// requests the basic stat: motd, game type, map, and player counts
+func (c *Client) Basic(ctx context.Context) (*BasicStat, error) {
+	res, err := c.stat(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{buf: res}
+	var stat BasicStat
+	stat.MOTD = r.cstring()
+	stat.GameType = r.cstring()
+	stat.Map = r.cstring()
+	stat.NumPlayers = r.cint()
+	stat.MaxPlayers = r.cint()
+	stat.HostPort = int(r.uint16LE())
+	stat.HostIP = r.cstring()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &stat, nil
+}
+
+// requests the full stat: every server value plus the version, plugins, and online player names
+func (c *Client) Full(ctx context.Context) (*FullStat, error) {
+	res, err := c.stat(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{buf: res}
+	r.expect(fullStatPadding)
+	stat := FullStat{Values: make(map[string]string)}
+	for {
+		key := r.cstring()
+		if key == "" || r.err != nil {
+			break
+		}
+		stat.Values[key] = r.cstring()
+	}
+	r.expect(playerPadding)
+	for {
+		name := r.cstring()
+		if name == "" || r.err != nil {
+			break
+		}
+		stat.Players = append(stat.Players, name)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	stat.MOTD = stat.Values["hostname"]
+	stat.GameType = stat.Values["gametype"]
+	stat.GameID = stat.Values["game_id"]
+	stat.Version = stat.Values["version"]
+	stat.Plugins = stat.Values["plugins"]
+	stat.Map = stat.Values["map"]
+	stat.NumPlayers, _ = strconv.Atoi(stat.Values["numplayers"])
+	stat.MaxPlayers, _ = strconv.Atoi(stat.Values["maxplayers"])
+	stat.HostPort, _ = strconv.Atoi(stat.Values["hostport"])
+	stat.HostIP = stat.Values["hostip"]
+	return &stat, nil
+}
+
+// performs the handshake and stat request, returning the stat payload following the response header
+func (c *Client) stat(ctx context.Context, full bool) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", net.JoinHostPort(c.address, strconv.Itoa(c.port)))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	session := rand.Int31() & 0x0f0f0f0f //the server ignores the high bits of each byte
+
+	res, err := c.exchange(ctx, conn, typeHandshake, session, nil)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err)
+	}
+	token, err := strconv.ParseInt(string(bytes.TrimRight(res, "\x00")), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid challenge token %q", ErrMalformedResponse, res)
+	}
+
+	payload := binary.BigEndian.AppendUint32(nil, uint32(token))
+	if full {
+		payload = append(payload, 0, 0, 0, 0)
+	}
+	res, err = c.exchange(ctx, conn, typeStat, session, payload)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err)
+	}
+	return res, nil
+}
+
+// writes a request and reads the matching response, returning the bytes after the type and session id. The
+// request is resent each retransmit interval until a reply arrives or the context ends
+func (c *Client) exchange(ctx context.Context, conn net.Conn, typ byte, session int32, payload []byte) ([]byte, error) {
+	req := append(append([]byte{}, magic...), typ)
+	req = binary.BigEndian.AppendUint32(req, uint32(session))
+	req = append(req, payload...)
+
+	buf := make([]byte, 65535)
+	var resend time.Time
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !time.Now().Before(resend) {
+			_, err := conn.Write(req)
+			if err != nil {
+				return nil, err
+			}
+			resend = c.readDeadline(ctx, conn)
+		}
+
+		n, err := conn.Read(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) && c.retransmit > 0 && !ctxerr.Expired(ctx) {
+			continue //retransmit interval passed without a reply
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n < 5 {
+			return nil, fmt.Errorf("%w: %d byte reply", ErrMalformedResponse, n)
+		}
+		if buf[0] != typ || int32(binary.BigEndian.Uint32(buf[1:5])) != session { //reply to an earlier request
+			continue
+		}
+		return buf[5:n], nil
+	}
+}
+
+// sets the read deadline to the next retransmission or the context deadline, whichever comes first, and
+// returns the time of the next retransmission
+func (c *Client) readDeadline(ctx context.Context, conn net.Conn) time.Time {
+	deadline, _ := ctx.Deadline()
+	if c.retransmit < 1 {
+		conn.SetReadDeadline(deadline)
+		return deadline
+	}
+	resend := time.Now().Add(c.retransmit)
+	if !deadline.IsZero() && deadline.Before(resend) {
+		resend = deadline
+	}
+	conn.SetReadDeadline(resend)
+	return resend
+}
+
+// sequential parser of null terminated fields, the first error is kept and later reads return zero values
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) cstring() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.buf, 0)
+	if i < 0 {
+		r.err = fmt.Errorf("%w: unterminated string", ErrMalformedResponse)
+		return ""
+	}
+	s := string(r.buf[:i])
+	r.buf = r.buf[i+1:]
+	return s
+}
+
+func (r *reader) cint() int {
+	s := r.cstring()
+	if r.err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		r.err = fmt.Errorf("%w: invalid number %q", ErrMalformedResponse, s)
+	}
+	return n
+}
+
+func (r *reader) uint16LE() uint16 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.buf) < 2 {
+		r.err = fmt.Errorf("%w: missing port", ErrMalformedResponse)
+		return 0
+	}
+	v := binary.LittleEndian.Uint16(r.buf)
+	r.buf = r.buf[2:]
+	return v
+}
+
+func (r *reader) expect(prefix []byte) {
+	if r.err != nil {
+		return
+	}
+	if !bytes.HasPrefix(r.buf, prefix) {
+		r.err = fmt.Errorf("%w: missing padding %q", ErrMalformedResponse, prefix)
+		return
+	}
+	r.buf = r.buf[len(prefix):]
+}
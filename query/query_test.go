@@ -0,0 +1,139 @@
+package query
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testToken = 9513307
+
+// starts a udp server answering handshakes and stat requests like a vanilla server, every request type is
+// ignored the first drop times it is received to simulate packet loss
+func startTestServer(t *testing.T, drop int) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		dropped := make(map[byte]int)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req := buf[:n]
+			if n < 7 || req[0] != 0xfe || req[1] != 0xfd {
+				continue
+			}
+			if dropped[req[2]] < drop {
+				dropped[req[2]]++
+				continue
+			}
+			res := append([]byte{req[2]}, req[3:7]...)
+			switch {
+			case req[2] == typeHandshake:
+				res = append(res, strconv.Itoa(testToken)+"\x00"...)
+			case n < 11 || binary.BigEndian.Uint32(req[7:11]) != testToken:
+				continue
+			case n == 11:
+				res = append(res, "A Minecraft Server\x00SMP\x00world\x002\x0020\x00"...)
+				res = binary.LittleEndian.AppendUint16(res, 25565)
+				res = append(res, "127.0.0.1\x00"...)
+			default:
+				res = append(res, fullStatPadding...)
+				res = append(res, "hostname\x00A Minecraft Server\x00gametype\x00SMP\x00game_id\x00MINECRAFT\x00"+
+					"version\x001.21\x00plugins\x00Paper on 1.21: Essentials\x00map\x00world\x00numplayers\x002\x00"+
+					"maxplayers\x0020\x00hostport\x0025565\x00hostip\x00127.0.0.1\x00\x00"...)
+				res = append(res, playerPadding...)
+				res = append(res, "Notch\x00jeb_\x00\x00"...)
+			}
+			conn.WriteTo(res, addr)
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// testing the basic stat request
+func TestBasic(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t, 0)))
+	stat, err := c.Basic(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BasicStat{MOTD: "A Minecraft Server", GameType: "SMP", Map: "world", NumPlayers: 2, MaxPlayers: 20,
+		HostPort: 25565, HostIP: "127.0.0.1"}
+	if *stat != want {
+		t.Fatalf("unexpected basic stat %+v", *stat)
+	}
+}
+
+// testing the full stat request
+func TestFull(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t, 0)))
+	stat, err := c.Full(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Version != "1.21" || stat.GameID != "MINECRAFT" || stat.Plugins != "Paper on 1.21: Essentials" {
+		t.Fatalf("unexpected full stat %+v", *stat)
+	}
+	if stat.NumPlayers != 2 || stat.HostPort != 25565 || len(stat.Values) != 10 {
+		t.Fatalf("unexpected full stat %+v", *stat)
+	}
+	if !reflect.DeepEqual(stat.Players, []string{"Notch", "jeb_"}) {
+		t.Fatalf("unexpected players %v", stat.Players)
+	}
+}
+
+// testing lost packets are retransmitted
+func TestRetransmit(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t, 2)), WithRetransmit(time.Millisecond*20))
+	stat, err := c.Basic(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.MaxPlayers != 20 {
+		t.Fatalf("unexpected basic stat %+v", *stat)
+	}
+
+	c = NewClient("127.0.0.1", WithPort(startTestServer(t, 1)), WithRetransmit(0), WithTimeout(time.Millisecond*100))
+	_, err = c.Basic(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded without retransmission, got %v", err)
+	}
+}
+
+// testing that unanswered requests give up once the timeout passes
+func TestTimeout(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	c := NewClient("127.0.0.1", WithPort(conn.LocalAddr().(*net.UDPAddr).Port), WithTimeout(time.Millisecond*100))
+	_, err = c.Basic(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+// testing malformed responses are rejected
+func TestMalformed(t *testing.T) {
+	r := &reader{buf: []byte("motd\x00SMP")}
+	r.cstring()
+	r.cstring()
+	if !errors.Is(r.err, ErrMalformedResponse) {
+		t.Fatalf("expected malformed response, got %v", r.err)
+	}
+}
@@ -0,0 +1,78 @@
+package mcr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// known deviations from the RCon standard, quirks can be combined using bitwise or
+type Quirk uint32
+
+const (
+	QuirkAuthJunkPacket      Quirk = 1 << iota //an empty response packet is sent ahead of the auth response
+	QuirkNoPadding                             //response bodies are not followed by the two padding bytes
+	QuirkSizeExcludesPadding                   //the Size header does not count the padding bytes after the body
+	QuirkServerPush                            //packets of unknown types such as chat broadcasts arrive between responses
+)
+
+// known server quirks keyed by fingerprint, a fingerprint is the lowercase name of the server software. Only
+// servers with a verified quirk are listed, fingerprints without an entry follow the standard. New variants
+// should be added here with the observed behavior and server version rather than as special cases in the
+// client
+var quirkRegistry = map[string]Quirk{
+	//srcds, every Source engine release: the auth request is answered with an empty RESPONSE_VALUE followed
+	//by the AUTH_RESPONSE, as described on the Valve Source RCON protocol page
+	"source": QuirkAuthJunkPacket,
+	//Squad dedicated server, every release with rcon: chat messages are pushed to each rcon connection as
+	//type 1 packets whether or not a command is in flight
+	"squad": QuirkServerPush,
+}
+
+// returns true if all quirks in q2 are set in q
+func (q Quirk) Has(q2 Quirk) bool {
+	return q&q2 == q2
+}
+
+// returns the known quirks for the server fingerprint and whether the fingerprint is registered
+func LookupQuirks(fingerprint string) (Quirk, bool) {
+	q, ok := quirkRegistry[fingerprint]
+	return q, ok
+}
+
+// quirks applied to the connection, quirks supplied with WithQuirks take priority over the registry
+func (c *Client) activeQuirks() Quirk {
+	if c.quirksSet {
+		return c.quirks
+	}
+	return quirkRegistry[c.fingerprint]
+}
+
+// names and descriptions of the quirks
+var quirkNames = []struct {
+	quirk       Quirk
+	name        string
+	description string
+}{
+	{QuirkAuthJunkPacket, "auth-junk-packet", "an empty response packet is sent ahead of the auth response"},
+	{QuirkNoPadding, "no-padding", "response bodies are not followed by the two padding bytes"},
+	{QuirkSizeExcludesPadding, "size-excludes-padding", "the Size header does not count the padding bytes after the body"},
+	{QuirkServerPush, "server-push", "packets of unknown types such as chat broadcasts arrive between responses"},
+}
+
+// returns the quirk names joined with |, or "none"
+func (q Quirk) String() string {
+	var names []string
+	for _, n := range quirkNames {
+		if q.Has(n.quirk) {
+			names = append(names, n.name)
+			q &^= n.quirk
+		}
+	}
+	if q != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint32(q)))
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
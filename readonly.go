@@ -0,0 +1,39 @@
+package mcr
+
+import (
+	"strings"
+)
+
+// commands that only query server state, allowed when the client is in read-only mode
+var queryCommands = []string{
+	"list",
+	"seed",
+	"data get",
+	"tps",
+	"help",
+	"banlist",
+	"whitelist list",
+}
+
+// returns true if the command only queries server state. Commands match on whole words so "data get" allows
+// "data get entity Steve" but not "data merge"
+func isQueryCommand(cmd string) bool {
+	fields := strings.Fields(strings.ToLower(strings.TrimLeft(cmd, "/ \t")))
+	for _, q := range queryCommands {
+		qf := strings.Fields(q)
+		if len(fields) < len(qf) {
+			continue
+		}
+		match := true
+		for i := range qf {
+			if fields[i] != qf[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
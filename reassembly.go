@@ -0,0 +1,178 @@
+package mcr
+
+import (
+	"fmt"
+	"time"
+)
+
+// strategy used to read command responses that the server splits across multiple packets
+type ReassemblyStrategy int
+
+const (
+	ReassemblyAuto     ReassemblyStrategy = iota //fragments are read while the previous fragment filled the maximum fragment size
+	ReassemblyNone                               //a single response packet is read for each command
+	ReassemblySentinel                           //an empty response packet is sent after the command and fragments are read until it is echoed
+	ReassemblyTimeout                            //fragments are read until no data arrives for the fragment timeout
+)
+
+// vanilla servers split responses into fragments of this many characters
+const MaxFragmentSize = 4096
+
+// sends a command packet and reads the full response using the configured reassembly strategy. Failed
+// exchanges mark the stream as stale so it is drained before the next request
+func (c *Client) roundTrip(packet []byte) (*Response, error) {
+	id := c.requestID
+	if c.stale {
+		_, err := c.Drain()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		res *Response
+		err error
+	)
+	switch c.reassembly {
+	case ReassemblySentinel:
+		res, err = c.roundTripSentinel(packet)
+	case ReassemblyTimeout:
+		res, err = c.roundTripTimeout(packet)
+	case ReassemblyNone:
+		res, err = c.send(packet)
+	default:
+		res, err = c.roundTripAuto(packet)
+	}
+	if err == nil && c.conformance {
+		err = checkResponse(res, id, ResponsePacket)
+	}
+	if err != nil {
+		c.stale = true
+		return nil, err
+	}
+	if res.Fragments > 1 {
+		res.Body = decodeBody(res.Raw, c.encoding)
+	}
+	return res, nil
+}
+
+// sends the command followed by an empty response value packet. Servers process packets in order so every
+// fragment of the command response arrives before the reply to the sentinel. Source servers mirror the
+// sentinel with an empty packet followed by a second packet, the second packet is consumed when it arrives
+// within the fragment timeout
+func (c *Client) roundTripSentinel(packet []byte) (*Response, error) {
+	cmdID := c.requestID
+	c.incrementRequestID()
+	sentinelID := c.requestID
+	sentinel, err := c.createPacket(nil, ResponsePacket)
+	if err != nil {
+		return nil, err
+	}
+	c.incrementRequestID()
+
+	_, err = c.connection.Write(append(packet, sentinel...))
+	if err != nil {
+		return nil, err
+	}
+
+	var full *Response
+	for {
+		res, err := c.recv()
+		if err != nil {
+			return nil, err
+		}
+
+		if res.RequestID == sentinelID {
+			if res.Body == "" {
+				_, err = c.readResponse(c.fragmentDeadline(), true)
+				if err != nil {
+					return nil, err
+				}
+			}
+			break
+		}
+
+		if res.RequestID != cmdID { //stale packet from an earlier request
+			continue
+		}
+		if full == nil {
+			full = res
+			continue
+		}
+		err = c.join(full, res)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if full == nil {
+		full = &Response{RequestID: cmdID, Type: ResponsePacket}
+	}
+	return full, nil
+}
+
+// sends the command and keeps reading while fragments are large enough to have been split by the server. A
+// fragment holding fewer bytes than the maximum fragment size is always the last one so most commands return
+// after a single packet, only full fragments wait for up to the fragment timeout for the next one
+func (c *Client) roundTripAuto(packet []byte) (*Response, error) {
+	full, err := c.send(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	last := len(full.Raw) //the server splits undecoded bytes, decoding can shorten the body
+	for last >= MaxFragmentSize {
+		res, err := c.readResponse(c.fragmentDeadline(), true)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			break
+		}
+		err = c.join(full, res)
+		if err != nil {
+			return nil, err
+		}
+		last = len(res.Raw)
+	}
+	return full, nil
+}
+
+// sends the command and reads fragments until the server is quiet for the fragment timeout
+func (c *Client) roundTripTimeout(packet []byte) (*Response, error) {
+	full, err := c.send(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		res, err := c.readResponse(c.fragmentDeadline(), true)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			return full, nil
+		}
+		err = c.join(full, res)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// appends the next fragment to a split response, enforcing the maximum response size
+func (c *Client) join(full, next *Response) error {
+	if c.strict && next.RequestID != full.RequestID {
+		return fmt.Errorf("%w: fragment of request %d arrived while reading request %d", ErrRequestIDMismatch, next.RequestID, full.RequestID)
+	}
+	if len(full.Raw)+len(next.Raw) > c.maxResponseSize {
+		return fmt.Errorf("%w: fragments exceed %d bytes", ErrResponseTooLarge, c.maxResponseSize)
+	}
+	full.append(next)
+	return nil
+}
+
+// deadline for the next response fragment
+func (c *Client) fragmentDeadline() time.Time {
+	return time.Now().Add(c.fragmentTimeout)
+}
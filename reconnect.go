@@ -0,0 +1,88 @@
+package mcr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// backoff used to redial after the connection breaks
+type reconnectPolicy struct {
+	min      time.Duration //delay before the second attempt
+	max      time.Duration //upper bound of the delay between attempts
+	attempts int           //maximum dial attempts for each broken connection
+}
+
+// option to redial and authenticate again when a command fails because the connection broke, for example
+// while the server restarts. Attempts are spaced by an exponential backoff starting at min and capped at max
+// with jitter applied. Failed query commands are retried once after reconnecting, other commands return the
+// error since the server may have run them before the connection broke
+func WithAutoReconnect(min, max time.Duration, attempts int) Option {
+	return func(cn *Client) {
+		if max < min {
+			max = min
+		}
+		if attempts < 1 {
+			attempts = 1
+		}
+		cn.reconnect = &reconnectPolicy{min: min, max: max, attempts: attempts}
+	}
+}
+
+// returns the delay before the attempt following the supplied one, half of the delay is randomized so clients
+// disconnected together do not redial in lockstep
+func (p *reconnectPolicy) delay(attempt int) time.Duration {
+	d := p.min
+	for i := 1; i < attempt && d < p.max; i++ {
+		d *= 2
+	}
+	if d > p.max {
+		d = p.max
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// closes the broken connection and redials until authentication succeeds, the attempts are exhausted, or the
+// context ends
+func (c *Client) redial(ctx context.Context) error {
+	c.disconnect()
+
+	var err error
+	for attempt := 1; attempt <= c.reconnect.attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("reconnect: %w: %v", ctx.Err(), err)
+			case <-time.After(c.reconnect.delay(attempt - 1)):
+			}
+		}
+
+		err = c.ConnectContext(ctx, c.password)
+		if err == nil {
+			return nil
+		}
+		c.disconnect()
+	}
+	return fmt.Errorf("reconnect: %w", err)
+}
+
+// returns true if the error shows the connection can no longer be used, timeouts and context errors are not
+// broken connections
+func isBroken(err error) bool {
+	return errors.Is(err, ErrConnectionClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, ErrTruncatedResponse) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,58 @@
+package mcr
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// restarts the server process after it has been stopped over RCon, implementations wrap process supervisors
+// such as systemd, docker, or hosting panel apis
+type Supervisor interface {
+	Restart(ctx context.Context) error
+}
+
+// adapter allowing ordinary functions to be used as a Supervisor
+type SupervisorFunc func(ctx context.Context) error
+
+func (f SupervisorFunc) Restart(ctx context.Context) error {
+	return f(ctx)
+}
+
+// timings of a coordinated restart
+type RestartReport struct {
+	StopResponse string    //server response to the stop command
+	StoppedAt    time.Time //time the stop command completed
+	RestartedAt  time.Time //time the supervisor finished restarting the process
+	AvailableAt  time.Time //time the client reconnected
+}
+
+// stops the server over RCon, asks the supervisor to restart the process, waits for RCon to accept the
+// password again, and reconnects the client. The client reconnects with its own options so tunnels, proxies,
+// and custom transports are used while waiting. The error identifies the phase that failed
+func (c *Client) Restart(ctx context.Context, sup Supervisor, password string, interval time.Duration) (*RestartReport, error) {
+	var report RestartReport
+	policy := c.reconnect
+	c.reconnect = nil //stop is never resent and the stopping server is not redialed
+	res, err := c.CommandContext(ctx, "stop")
+	c.reconnect = policy
+	if err != nil && !isBroken(err) { //servers may close the connection before or while replying
+		return &report, fmt.Errorf("restart: stop: %w", err)
+	}
+	report.StopResponse = res
+	report.StoppedAt = time.Now()
+	c.disconnect()
+
+	err = sup.Restart(ctx)
+	if err != nil {
+		return &report, fmt.Errorf("restart: supervisor: %w", err)
+	}
+	report.RestartedAt = time.Now()
+
+	err = c.waitConnect(ctx, password, interval)
+	if err != nil {
+		return &report, fmt.Errorf("restart: connect: %w", err)
+	}
+	report.AvailableAt = time.Now()
+	return &report, nil
+}
@@ -0,0 +1,44 @@
+package mcr
+
+import (
+	"strings"
+	"unicode"
+)
+
+// policy for commands containing control characters, newlines, or section signs. Servers treat a newline as
+// the end of a command and section signs as formatting codes, so text copied from users into a command can
+// run a second command or spoof formatted messages
+type SanitizePolicy struct {
+	Reject           bool //fail the command with ErrUnsafeCommand instead of removing the characters
+	AllowSectionSign bool //keep section signs so say and tellraw can send formatting codes
+}
+
+// option to remove or reject unsafe characters in outgoing commands, the zero policy removes them
+func WithSanitization(policy SanitizePolicy) Option {
+	return func(cn *Client) {
+		cn.sanitize = &policy
+	}
+}
+
+// applies the policy to the command, a nil policy leaves the command unchanged
+func (p *SanitizePolicy) apply(cmd string) (string, error) {
+	if p == nil {
+		return cmd, nil
+	}
+
+	unsafe := func(r rune) bool {
+		return unicode.IsControl(r) || (r == '§' && !p.AllowSectionSign)
+	}
+	if strings.IndexFunc(cmd, unsafe) < 0 {
+		return cmd, nil
+	}
+	if p.Reject {
+		return "", ErrUnsafeCommand
+	}
+	return strings.Map(func(r rune) rune {
+		if unsafe(r) {
+			return -1
+		}
+		return r
+	}, cmd), nil
+}
@@ -0,0 +1,297 @@
+// Package selector builds and validates Minecraft target selectors such as @a[distance=..10,team=red] so
+// automation catches malformed selectors before the server rejects them with a confusing error
+package selector
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// selector variables
+const (
+	NearestPlayer = 'p'
+	RandomPlayer  = 'r'
+	AllPlayers    = 'a'
+	AllEntities   = 'e'
+	Self          = 's'
+	NearestEntity = 'n'
+)
+
+var (
+	rangePattern    = regexp.MustCompile(`^(-?\d+(\.\d+)?)?(\.\.)?(-?\d+(\.\d+)?)?$`)
+	resourcePattern = regexp.MustCompile(`^!?#?([a-z0-9_.-]+:)?[a-z0-9_./-]+$`)
+	namePattern     = regexp.MustCompile(`^!?([A-Za-z0-9_.+-]+|"[^"]*")?$`)
+
+	sorts     = map[string]bool{"nearest": true, "furthest": true, "random": true, "arbitrary": true}
+	gamemodes = map[string]bool{"survival": true, "creative": true, "adventure": true, "spectator": true}
+)
+
+// selector argument
+type argument struct {
+	key   string
+	value string
+}
+
+// target selector builder, arguments are validated as they are added and the first error is returned by Build
+type Selector struct {
+	variable rune
+	args     []argument
+	err      error
+}
+
+// creates a selector for the variable, one of the selector variable constants
+func New(variable rune) *Selector {
+	s := &Selector{variable: variable}
+	if !strings.ContainsRune("prsaen", variable) {
+		s.err = fmt.Errorf("invalid selector variable @%c", variable)
+	}
+	return s
+}
+
+// selects players within the distance range, see Range
+func (s *Selector) Distance(r Range) *Selector {
+	if r.min != nil && *r.min < 0 {
+		return s.fail(errors.New("distance cannot be negative"))
+	}
+	return s.add("distance", r.String())
+}
+
+// selects players within the experience level range
+func (s *Selector) Level(r Range) *Selector {
+	return s.add("level", r.String())
+}
+
+// selects entities on the team, prefix the name with ! to negate
+func (s *Selector) Team(team string) *Selector {
+	return s.add("team", team)
+}
+
+// selects entities with the tag, prefix the tag with ! to negate
+func (s *Selector) Tag(tag string) *Selector {
+	return s.add("tag", tag)
+}
+
+// selects entities with the name, prefix the name with ! to negate
+func (s *Selector) Name(name string) *Selector {
+	return s.add("name", name)
+}
+
+// selects entities of the type, for example minecraft:zombie or !minecraft:player
+func (s *Selector) Type(entity string) *Selector {
+	return s.add("type", entity)
+}
+
+// selects players in the game mode, prefix the mode with ! to negate
+func (s *Selector) Gamemode(mode string) *Selector {
+	return s.add("gamemode", mode)
+}
+
+// limits the number of selected entities
+func (s *Selector) Limit(n int) *Selector {
+	return s.add("limit", strconv.Itoa(n))
+}
+
+// sorts the selected entities, one of nearest, furthest, random, or arbitrary
+func (s *Selector) Sort(order string) *Selector {
+	return s.add("sort", order)
+}
+
+// adds any other argument after validating it
+func (s *Selector) Arg(key, value string) *Selector {
+	return s.add(key, value)
+}
+
+// returns the selector or the first validation error
+func (s *Selector) Build() (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+
+	sel := "@" + string(s.variable)
+	if len(s.args) == 0 {
+		return sel, nil
+	}
+	parts := make([]string, len(s.args))
+	for i, a := range s.args {
+		parts[i] = a.key + "=" + a.value
+	}
+	return sel + "[" + strings.Join(parts, ",") + "]", nil
+}
+
+// returns the selector, invalid selectors return an empty string
+func (s *Selector) String() string {
+	sel, _ := s.Build()
+	return sel
+}
+
+func (s *Selector) add(key, value string) *Selector {
+	if s.err != nil {
+		return s
+	}
+	err := validateArg(key, value)
+	if err != nil {
+		return s.fail(err)
+	}
+	s.args = append(s.args, argument{key: key, value: value})
+	return s
+}
+
+func (s *Selector) fail(err error) *Selector {
+	if s.err == nil {
+		s.err = err
+	}
+	return s
+}
+
+// numeric range used by distance, level, and rotation arguments
+type Range struct {
+	min *float64
+	max *float64
+}
+
+// matches exactly the value
+func Exactly(v float64) Range {
+	return Range{min: &v, max: &v}
+}
+
+// matches values at least min
+func AtLeast(min float64) Range {
+	return Range{min: &min}
+}
+
+// matches values at most max
+func AtMost(max float64) Range {
+	return Range{max: &max}
+}
+
+// matches values between min and max inclusive
+func Between(min, max float64) Range {
+	return Range{min: &min, max: &max}
+}
+
+// returns the range in selector syntax, for example ..10 or 1..5
+func (r Range) String() string {
+	format := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	if r.min != nil && r.max != nil && *r.min == *r.max {
+		return format(r.min)
+	}
+	return format(r.min) + ".." + format(r.max)
+}
+
+// validates a selector such as @a[distance=..10,team=red]
+func Validate(sel string) error {
+	if len(sel) < 2 || sel[0] != '@' || !strings.ContainsRune("prsaen", rune(sel[1])) {
+		return fmt.Errorf("invalid selector variable in %q", sel)
+	}
+	rest := sel[2:]
+	if rest == "" {
+		return nil
+	}
+	if rest[0] != '[' || rest[len(rest)-1] != ']' {
+		return fmt.Errorf("selector arguments must be enclosed in brackets in %q", sel)
+	}
+
+	args, err := splitArgs(rest[1 : len(rest)-1])
+	if err != nil {
+		return err
+	}
+	for _, a := range args {
+		key, value, ok := strings.Cut(a, "=")
+		if !ok {
+			return fmt.Errorf("selector argument %q is missing a value", a)
+		}
+		err = validateArg(strings.TrimSpace(key), strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splits selector arguments on commas outside of braces, brackets, and quotes
+func splitArgs(s string) ([]string, error) {
+	var (
+		args   []string
+		depth  int
+		quoted bool
+		start  int
+	)
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '{' || r == '[':
+			depth++
+		case r == '}' || r == ']':
+			depth--
+			if depth < 0 {
+				return nil, errors.New("unbalanced brackets in selector arguments")
+			}
+		case r == ',' && depth == 0:
+			args = append(args, s[start:i])
+			start = i + 1
+		}
+	}
+	if depth != 0 || quoted {
+		return nil, errors.New("unbalanced brackets or quotes in selector arguments")
+	}
+	return append(args, s[start:]), nil
+}
+
+// validates the value of a single selector argument
+func validateArg(key, value string) error {
+	switch key {
+	case "x", "y", "z", "dx", "dy", "dz":
+		_, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, got %q", key, value)
+		}
+	case "distance", "level", "x_rotation", "y_rotation":
+		if value == "" || value == ".." || !rangePattern.MatchString(value) {
+			return fmt.Errorf("%s must be a range such as 5, ..10, or 1..5, got %q", key, value)
+		}
+		if key == "distance" && strings.HasPrefix(value, "-") {
+			return errors.New("distance cannot be negative")
+		}
+	case "limit":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("limit must be a positive integer, got %q", value)
+		}
+	case "sort":
+		if !sorts[value] {
+			return fmt.Errorf("sort must be nearest, furthest, random, or arbitrary, got %q", value)
+		}
+	case "gamemode":
+		if !gamemodes[strings.TrimPrefix(value, "!")] {
+			return fmt.Errorf("invalid gamemode %q", value)
+		}
+	case "type", "predicate":
+		if !resourcePattern.MatchString(value) {
+			return fmt.Errorf("invalid %s resource location %q", key, value)
+		}
+	case "name", "team", "tag":
+		if !namePattern.MatchString(value) {
+			return fmt.Errorf("invalid %s %q", key, value)
+		}
+	case "scores", "advancements", "nbt":
+		if len(value) < 2 || value[0] != '{' || value[len(value)-1] != '}' {
+			return fmt.Errorf("%s must be enclosed in braces, got %q", key, value)
+		}
+	default:
+		return fmt.Errorf("unknown selector argument %q", key)
+	}
+	return nil
+}
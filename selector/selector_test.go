@@ -0,0 +1,48 @@
+package selector
+
+import (
+	"testing"
+)
+
+// testing selectors built with the builder
+func TestBuild(t *testing.T) {
+	sel, err := New(AllPlayers).Distance(AtMost(10)).Team("red").Limit(3).Sort("nearest").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel != "@a[distance=..10,team=red,limit=3,sort=nearest]" {
+		t.Fatalf("unexpected selector %s", sel)
+	}
+	if err := Validate(sel); err != nil {
+		t.Fatalf("built selector failed validation: %v", err)
+	}
+
+	if _, err := New(AllEntities).Limit(0).Build(); err == nil {
+		t.Fatal("expected a zero limit to be rejected")
+	}
+	if _, err := New('q').Build(); err == nil {
+		t.Fatal("expected an invalid variable to be rejected")
+	}
+}
+
+// testing selector validation
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"@s",
+		"@e[type=!minecraft:player,distance=1..5]",
+		`@a[scores={kills=1..},nbt={Inventory:[{id:"minecraft:diamond"}]}]`,
+		`@p[name="Some Name",gamemode=!spectator]`,
+	}
+	for _, sel := range valid {
+		if err := Validate(sel); err != nil {
+			t.Fatalf("valid selector %s was rejected: %v", sel, err)
+		}
+	}
+
+	invalid := []string{"@x", "@a[", "@a[distance=abc]", "@a[limit=-1]", "@a[colour=red]", "@a[sort=closest]", "@a[scores={a=1]"}
+	for _, sel := range invalid {
+		if err := Validate(sel); err == nil {
+			t.Fatalf("invalid selector %s was accepted", sel)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package mcr
+
+import (
+	"time"
+)
+
+// usage of a session lasting from the first successful connect until Close, byte counts cover command and
+// response bodies
+type SessionSummary struct {
+	Address       string
+	Labels        map[string]string
+	Start         time.Time
+	End           time.Time
+	Duration      time.Duration
+	Commands      int   //commands sent including failed commands
+	Errors        int   //commands that returned an error
+	BytesSent     int64 //command body bytes
+	BytesReceived int64 //response body bytes including every fragment
+	Reconnects    int   //connections re-established within the session
+}
+
+// option to send a summary of each session to the sink when Close ends it, for per-session usage reporting
+// in hosting panels. Automatic reconnects, ReconnectContext, and Restart stay within the session
+func WithSessionSummary(sink func(SessionSummary)) Option {
+	return func(cn *Client) {
+		cn.session = &sessionTracker{sink: sink}
+	}
+}
+
+// counters of the session in progress
+type sessionTracker struct {
+	sink    func(SessionSummary)
+	active  bool
+	summary SessionSummary
+}
+
+// records a successful connection, starting a session or counting a reconnect within the active session
+func (s *sessionTracker) connected(c *Client) {
+	if s == nil {
+		return
+	}
+	if s.active {
+		s.summary.Reconnects++
+		return
+	}
+	s.active = true
+	s.summary = SessionSummary{Address: c.address, Labels: c.Labels(), Start: time.Now()}
+}
+
+// records a command and its response
+func (s *sessionTracker) command(cmd string, res *Response, err error) {
+	if s == nil || !s.active {
+		return
+	}
+	s.summary.Commands++
+	s.summary.BytesSent += int64(len(cmd))
+	if err != nil {
+		s.summary.Errors++
+	}
+	if res != nil {
+		s.summary.BytesReceived += int64(len(res.Raw))
+	}
+}
+
+// ends the active session and sends its summary to the sink
+func (s *sessionTracker) end() {
+	if s == nil || !s.active {
+		return
+	}
+	s.active = false
+	s.summary.End = time.Now()
+	s.summary.Duration = s.summary.End.Sub(s.summary.Start)
+	if s.sink != nil {
+		s.sink(s.summary)
+	}
+}
@@ -0,0 +1,253 @@
+// Package sim implements a deterministic RCon server that behaves like a vanilla Minecraft server, so
+// projects built on mcr can run tests in CI without a real server. It supports authentication, responses
+// split into fragments, canned responses, injected latency, and the Latin-1 and escaped body encodings of
+// servers that do not send UTF-8
+package sim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+const (
+	//default values
+	DefaultFragmentSize = mcr.MaxFragmentSize
+	DefaultMaxPlayers   = 20
+)
+
+// simulated server, the zero value accepts any password and uses the vanilla responses
+type Server struct {
+	Password     string            //password required to authenticate, empty accepts any password
+	FragmentSize int               //maximum body bytes per response packet
+	Latency      time.Duration     //delay before each response is written
+	Players      []string          //players reported as online
+	Responses    map[string]string //canned responses keyed by exact command, checked before the built-in commands
+	Encoding     mcr.Encoding      //encoding of response bodies, EncodingAuto and EncodingUTF8 send UTF-8
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+}
+
+// starts listening on the address and serves connections in the background
+func (s *Server) Listen(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.listener = ln
+	s.conns = make(map[net.Conn]struct{})
+	s.mu.Unlock()
+
+	go s.serve(ln)
+	return nil
+}
+
+// returns the address the server is listening on
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// stops listening and closes all connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	err := s.listener.Close()
+	s.listener = nil
+	return err
+}
+
+func (s *Server) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+// handles a single client connection until it closes
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	authed := false
+	for {
+		id, typ, body, err := readPacket(conn)
+		if err != nil {
+			return
+		}
+		if s.Latency > 0 {
+			time.Sleep(s.Latency)
+		}
+
+		switch {
+		case typ == mcr.AuthPacket:
+			authed = s.Password == "" || body == s.Password
+			if !authed {
+				id = mcr.FailurePacket
+			}
+			err = writePacket(conn, id, mcr.AuthResponsePacket, "")
+		case !authed: //vanilla servers drop connections that send commands before authenticating
+			return
+		case typ == mcr.CommandPacket:
+			err = s.respond(conn, id, encodeBody(s.execute(body), s.Encoding))
+		default:
+			err = writePacket(conn, id, mcr.ResponsePacket, fmt.Sprintf("Unknown request %x", int32(typ)))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writes the response split into fragments, multi-byte characters may be split across fragments like a real
+// server
+func (s *Server) respond(conn net.Conn, id int32, res string) error {
+	size := s.FragmentSize
+	if size <= 0 {
+		size = DefaultFragmentSize
+	}
+	for {
+		n := len(res)
+		if n > size {
+			n = size
+		}
+		err := writePacket(conn, id, mcr.ResponsePacket, res[:n])
+		if err != nil {
+			return err
+		}
+		res = res[n:]
+		if res == "" {
+			return nil
+		}
+	}
+}
+
+// returns the response to a command
+func (s *Server) execute(cmd string) string {
+	cmd = strings.TrimPrefix(cmd, "/")
+	if res, ok := s.Responses[cmd]; ok {
+		return res
+	}
+
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return "Unknown or incomplete command, see below for error<--[HERE]"
+	}
+	switch fields[0] {
+	case "list":
+		return fmt.Sprintf("There are %d of a max of %d players online: %s", len(s.Players), DefaultMaxPlayers, strings.Join(s.Players, ", "))
+	case "seed":
+		return "Seed: [0]"
+	case "say", "stop", "save-all":
+		return ""
+	case "kick":
+		if len(fields) > 1 {
+			for _, p := range s.Players {
+				if p == fields[1] {
+					return fmt.Sprintf("Kicked %s: Kicked by an operator", p)
+				}
+			}
+		}
+		return "No player was found"
+	case "help":
+		return strings.Repeat("/help [<command>]\n", 512) //long enough to be split into fragments
+	}
+	return fmt.Sprintf("Unknown or incomplete command, see below for error%s<--[HERE]", cmd)
+}
+
+// encodes a response body the way servers using the encoding send it, characters Latin-1 cannot represent are
+// replaced with ? and non-ascii characters are escaped as \uXXXX with surrogate pairs outside the BMP
+func encodeBody(body string, enc mcr.Encoding) string {
+	switch enc {
+	case mcr.EncodingLatin1:
+		buf := make([]byte, 0, len(body))
+		for _, r := range body {
+			if r > 0xff {
+				r = '?'
+			}
+			buf = append(buf, byte(r))
+		}
+		return string(buf)
+	case mcr.EncodingEscaped:
+		var sb strings.Builder
+		for _, r := range body {
+			switch {
+			case r < 0x80:
+				sb.WriteRune(r)
+			case r > 0xffff:
+				r1, r2 := utf16.EncodeRune(r)
+				fmt.Fprintf(&sb, `\u%04x\u%04x`, r1, r2)
+			default:
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			}
+		}
+		return sb.String()
+	}
+	return body
+}
+
+// reads a request packet
+func readPacket(conn net.Conn) (int32, mcr.PacketType, string, error) {
+	var size int32
+	err := binary.Read(conn, binary.LittleEndian, &size)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if size < mcr.PacketRequestSize || size > 1<<16 {
+		return 0, 0, "", errors.New("invalid request size")
+	}
+
+	buf := make([]byte, size)
+	_, err = io.ReadFull(conn, buf)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	id := int32(binary.LittleEndian.Uint32(buf[0:4]))
+	typ := mcr.PacketType(binary.LittleEndian.Uint32(buf[4:8]))
+	return id, typ, string(buf[mcr.PacketHeaderSize : size-mcr.PacketPaddingSize]), nil
+}
+
+// writes a response packet
+func writePacket(conn net.Conn, id int32, typ mcr.PacketType, body string) error {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, int32(len(body)+mcr.PacketRequestSize))
+	binary.Write(&buf, binary.LittleEndian, id)
+	binary.Write(&buf, binary.LittleEndian, typ)
+	buf.WriteString(body)
+	buf.Write(make([]byte, mcr.PacketPaddingSize))
+	_, err := conn.Write(buf.Bytes())
+	return err
+}
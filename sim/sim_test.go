@@ -0,0 +1,92 @@
+package sim
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+// starts a simulator on a random local port and returns a client configured for it
+func newTestClient(t *testing.T, s *Server, opts ...mcr.Option) *mcr.Client {
+	err := s.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	addr := s.Addr().(*net.TCPAddr)
+	return mcr.NewClient(addr.IP.String(), append(opts, mcr.WithPort(addr.Port))...)
+}
+
+// testing authentication against the simulator
+func TestAuth(t *testing.T) {
+	c := newTestClient(t, &Server{Password: "secret"})
+	if c.Connect("wrong") == nil {
+		t.Fatal("wrong password was accepted")
+	}
+	c.Close()
+
+	err := c.Connect("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	res, err := c.Command("kick Steve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mcr.Classify(res) != mcr.OutcomePlayerNotFound {
+		t.Fatalf("unexpected kick response %q", res)
+	}
+}
+
+// testing fragmented responses are reassembled by the client
+func TestFragmentation(t *testing.T) {
+	c := newTestClient(t, &Server{FragmentSize: 100}, mcr.WithReassemblyStrategy(mcr.ReassemblySentinel))
+	err := c.Connect("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	res, err := c.Command("help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != strings.Repeat("/help [<command>]\n", 512) {
+		t.Fatalf("fragmented response was not reassembled, got %d bytes", len(res))
+	}
+}
+
+// testing bodies sent in each encoding are decoded by the client, including characters split across fragments
+func TestEncoding(t *testing.T) {
+	motd := strings.Repeat("a", 99) + "é Jörg grüßt 😀"
+	cases := []struct {
+		enc  mcr.Encoding
+		want string
+	}{
+		{mcr.EncodingUTF8, motd},
+		{mcr.EncodingLatin1, strings.Repeat("a", 99) + "é Jörg grüßt ?"},
+		{mcr.EncodingEscaped, motd},
+	}
+	for _, tc := range cases {
+		s := &Server{FragmentSize: 100, Encoding: tc.enc, Responses: map[string]string{"motd": motd}}
+		c := newTestClient(t, s, mcr.WithReassemblyStrategy(mcr.ReassemblySentinel))
+		err := c.Connect("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := c.Command("motd")
+		c.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != tc.want {
+			t.Fatalf("encoding %d: expected %q, got %q", tc.enc, tc.want, res)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package mcr
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// upper bounds of the latency histogram buckets, the last bucket holds every slower command
+var slowLogBounds = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	math.MaxInt64,
+}
+
+// command that took longer than the slow log threshold
+type SlowCommand struct {
+	Command      string        //command as sent
+	At           time.Time     //time the command was sent
+	Latency      time.Duration //round trip time
+	Fingerprint  string        //server software set with WithFingerprint
+	ResponseSize int           //response body bytes across every fragment
+	Fragments    int           //number of packets the response was split across
+	Err          error         //error returned for the command, nil when it succeeded
+}
+
+// number of commands with a latency at or below the upper bound and above the previous bucket
+type HistogramBucket struct {
+	UpperBound time.Duration
+	Count      int
+}
+
+// snapshot of the slow log
+type SlowLog struct {
+	Entries   []SlowCommand     //slowest recent commands, oldest first
+	Histogram []HistogramBucket //latency of every command since the client was created
+}
+
+// option to record commands slower than the threshold in a ring buffer holding the most recent entries, each
+// entry is also passed to the sink when it is not nil. The sink runs before the command returns so it should
+// hand entries off rather than block. Every command latency is counted in a histogram to put the entries in
+// context, see Client.SlowLog
+func WithSlowLog(threshold time.Duration, entries int, sink func(SlowCommand)) Option {
+	return func(cn *Client) {
+		if entries < 1 {
+			entries = 1
+		}
+		cn.slowLog = &slowLog{
+			threshold: threshold,
+			sink:      sink,
+			entries:   make([]SlowCommand, 0, entries),
+			counts:    make([]int, len(slowLogBounds)),
+		}
+	}
+}
+
+// returns a snapshot of the slow log, the snapshot is empty unless WithSlowLog was supplied
+func (c *Client) SlowLog() SlowLog {
+	return c.slowLog.snapshot()
+}
+
+// ring buffer of slow commands and latency histogram, safe for concurrent use so the log can be read while
+// commands run
+type slowLog struct {
+	threshold time.Duration
+	sink      func(SlowCommand)
+
+	mu      sync.Mutex
+	entries []SlowCommand //ring buffer, next is the oldest entry once it is full
+	next    int
+	counts  []int //histogram counts indexed like slowLogBounds
+}
+
+// counts the command latency and keeps the command when it exceeds the threshold
+func (l *slowLog) record(entry SlowCommand) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	for i, bound := range slowLogBounds {
+		if entry.Latency <= bound {
+			l.counts[i]++
+			break
+		}
+	}
+	slow := entry.Latency > l.threshold
+	if slow {
+		if len(l.entries) < cap(l.entries) {
+			l.entries = append(l.entries, entry)
+		} else {
+			l.entries[l.next] = entry
+			l.next = (l.next + 1) % len(l.entries)
+		}
+	}
+	l.mu.Unlock()
+
+	if slow && l.sink != nil {
+		l.sink(entry)
+	}
+}
+
+// copies the entries in the order they were recorded along with the histogram
+func (l *slowLog) snapshot() SlowLog {
+	if l == nil {
+		return SlowLog{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var log SlowLog
+	log.Entries = append(log.Entries, l.entries[l.next:]...)
+	log.Entries = append(log.Entries, l.entries[:l.next]...)
+	log.Histogram = make([]HistogramBucket, len(slowLogBounds))
+	for i, bound := range slowLogBounds {
+		log.Histogram[i] = HistogramBucket{UpperBound: bound, Count: l.counts[i]}
+	}
+	return log
+}
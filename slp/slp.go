@@ -0,0 +1,268 @@
+// Package slp implements the Minecraft Server List Ping protocol used by the multiplayer menu, returning the
+// motd, version, player counts, and latency of a Java server without RCon credentials
+package slp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jake-young-dev/mcr/internal/ctxerr"
+)
+
+const (
+	//default values
+	DefaultPort            = 25565
+	DefaultTimeout         = time.Second * 5
+	DefaultProtocolVersion = -1 //asks the server to report its own version
+
+	//packet ids
+	packetHandshake = 0x00
+	packetStatus    = 0x00
+	packetPing      = 0x01
+
+	//next state requested by the handshake
+	stateStatus = 1
+
+	//bound on a status response, favicons make them a few kilobytes
+	maxPacketSize = 1 << 21
+)
+
+// returned when the server reply cannot be parsed
+var ErrMalformedResponse = errors.New("malformed status response")
+
+// server status returned by the status request
+type Status struct {
+	Version            Version       `json:"version"`
+	Players            Players       `json:"players"`
+	Description        Description   `json:"description"`
+	Favicon            string        `json:"favicon,omitempty"` //png data uri
+	EnforcesSecureChat bool          `json:"enforcesSecureChat,omitempty"`
+	Raw                []byte        `json:"-"` //json sent by the server
+	Latency            time.Duration `json:"-"` //round trip of the ping following the status request
+}
+
+// server software name and protocol number
+type Version struct {
+	Name     string `json:"name"`
+	Protocol int    `json:"protocol"`
+}
+
+// player counts and the sample of online players, servers may hide or fake the sample
+type Players struct {
+	Max    int      `json:"max"`
+	Online int      `json:"online"`
+	Sample []Player `json:"sample,omitempty"`
+}
+
+// player listed in the status sample
+type Player struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+// motd sent as either a plain string or a chat component, Text holds the motd with formatting removed
+type Description struct {
+	Text string
+	Raw  json.RawMessage
+}
+
+func (d *Description) UnmarshalJSON(b []byte) error {
+	d.Raw = append(json.RawMessage{}, b...)
+	var v any
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	flatten(&sb, v)
+	d.Text = sb.String()
+	return nil
+}
+
+func (d Description) MarshalJSON() ([]byte, error) {
+	if d.Raw != nil {
+		return d.Raw, nil
+	}
+	return json.Marshal(d.Text)
+}
+
+// appends the text of a chat component and its children
+func flatten(sb *strings.Builder, v any) {
+	switch c := v.(type) {
+	case string:
+		sb.WriteString(c)
+	case []any:
+		for _, e := range c {
+			flatten(sb, e)
+		}
+	case map[string]any:
+		flatten(sb, c["text"])
+		flatten(sb, c["extra"])
+	}
+}
+
+// server list ping client, each request opens a new connection so the client is safe for concurrent use
+type Client struct {
+	address  string
+	port     int
+	timeout  time.Duration
+	protocol int
+}
+
+// client option func skeleton
+type Option func(c *Client)
+
+// option to allow for custom port values
+func WithPort(port int) Option {
+	return func(c *Client) {
+		c.port = port
+	}
+}
+
+// option to allow for custom timeouts, the timeout bounds each request including the connection
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// option to send a specific protocol version in the handshake, some proxies answer with the motd configured
+// for that version
+func WithProtocolVersion(version int) Option {
+	return func(c *Client) {
+		c.protocol = version
+	}
+}
+
+// creates a new server list ping client configured with the supplied options
+func NewClient(addr string, opts ...Option) *Client {
+	c := &Client{
+		address:  addr,
+		port:     DefaultPort,
+		timeout:  DefaultTimeout,
+		protocol: DefaultProtocolVersion,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// requests the server status and measures latency with a ping
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(c.address, strconv.Itoa(c.port)))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	status, err := c.status(conn)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err)
+	}
+	return status, nil
+}
+
+func (c *Client) status(conn net.Conn) (*Status, error) {
+	//the handshake and status request are sent together as the vanilla client does
+	handshake := appendVarInt([]byte{packetHandshake}, int32(c.protocol))
+	handshake = appendString(handshake, c.address)
+	handshake = binary.BigEndian.AppendUint16(handshake, uint16(c.port))
+	handshake = appendVarInt(handshake, stateStatus)
+	req := appendPacket(nil, handshake)
+	req = appendPacket(req, []byte{packetStatus})
+	_, err := conn.Write(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	body, err := readPacket(r, packetStatus)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := readString(body)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Raw: raw}
+	err = json.Unmarshal(raw, status)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedResponse, err)
+	}
+
+	//some servers close the connection instead of answering the ping, keep the status without a latency
+	payload := time.Now().UnixMilli()
+	start := time.Now()
+	_, err = conn.Write(appendPacket(nil, binary.BigEndian.AppendUint64([]byte{packetPing}, uint64(payload))))
+	if err != nil {
+		return status, nil
+	}
+	pong, err := readPacket(r, packetPing)
+	if err != nil || len(pong) != 8 || int64(binary.BigEndian.Uint64(pong)) != payload {
+		return status, nil
+	}
+	status.Latency = time.Since(start)
+	return status, nil
+}
+
+// reads a length prefixed packet and returns its body after checking the packet id
+func readPacket(r *bufio.Reader, id int32) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size < 1 || size > maxPacketSize {
+		return nil, fmt.Errorf("%w: packet size %d", ErrMalformedResponse, size)
+	}
+	buf := make([]byte, size)
+	_, err = io.ReadFull(r, buf)
+	if err != nil {
+		return nil, err
+	}
+	got, n := binary.Uvarint(buf)
+	if n <= 0 || int32(got) != id {
+		return nil, fmt.Errorf("%w: unexpected packet id %d", ErrMalformedResponse, got)
+	}
+	return buf[n:], nil
+}
+
+// reads a varint length prefixed string
+func readString(b []byte) ([]byte, error) {
+	size, n := binary.Uvarint(b)
+	if n <= 0 || size > uint64(len(b)-n) {
+		return nil, fmt.Errorf("%w: invalid string length", ErrMalformedResponse)
+	}
+	return b[n : n+int(size)], nil
+}
+
+// protocol varints are 32 bit two's complement values encoded as unsigned leb128
+func appendVarInt(b []byte, v int32) []byte {
+	return binary.AppendUvarint(b, uint64(uint32(v)))
+}
+
+func appendString(b []byte, s string) []byte {
+	b = appendVarInt(b, int32(len(s)))
+	return append(b, s...)
+}
+
+func appendPacket(b, body []byte) []byte {
+	b = appendVarInt(b, int32(len(body)))
+	return append(b, body...)
+}
@@ -0,0 +1,123 @@
+package slp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+const testStatus = `{"version":{"name":"1.21","protocol":767},"players":{"max":20,"online":1,` +
+	`"sample":[{"name":"Notch","id":"069a79f4-44e9-4726-a5be-fca90e38aaf5"}]},` +
+	`"description":{"text":"A ","extra":[{"text":"Minecraft","bold":true}," Server"]}}`
+
+// starts a tcp server answering status and ping requests like a vanilla server
+func startTestServer(t *testing.T, ping bool) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				handshake, err := readPacket(r, packetHandshake)
+				if err != nil {
+					return
+				}
+				if next := handshake[len(handshake)-1]; next != stateStatus {
+					return
+				}
+				_, err = readPacket(r, packetStatus)
+				if err != nil {
+					return
+				}
+				conn.Write(appendPacket(nil, appendString([]byte{packetStatus}, testStatus)))
+				pong, err := readPacket(r, packetPing)
+				if err != nil || !ping {
+					return
+				}
+				conn.Write(appendPacket(nil, append([]byte{packetPing}, pong...)))
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// testing the status request and ping
+func TestStatus(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t, true)))
+	status, err := c.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Version.Protocol != 767 || status.Players.Online != 1 || status.Players.Sample[0].Name != "Notch" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status.Description.Text != "A Minecraft Server" {
+		t.Fatalf("unexpected motd %q", status.Description.Text)
+	}
+	if status.Latency <= 0 {
+		t.Fatal("expected a latency")
+	}
+	if string(status.Raw) != testStatus {
+		t.Fatalf("unexpected raw status %s", status.Raw)
+	}
+}
+
+// testing servers that close the connection instead of answering the ping
+func TestStatusWithoutPing(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t, false)))
+	status, err := c.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Latency != 0 || status.Version.Name != "1.21" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+// testing a silent server reports the expired deadline instead of an i/o timeout
+func TestStatusTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	c := NewClient("127.0.0.1", WithPort(ln.Addr().(*net.TCPAddr).Port), WithTimeout(50*time.Millisecond))
+	_, err = c.Status(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// testing negative protocol versions are encoded as five byte varints
+func TestVarInt(t *testing.T) {
+	b := appendVarInt(nil, -1)
+	if len(b) != 5 {
+		t.Fatalf("expected five bytes, got %x", b)
+	}
+	v, _ := binary.Uvarint(b)
+	if int32(v) != -1 {
+		t.Fatalf("unexpected value %d", int32(v))
+	}
+}
@@ -0,0 +1,36 @@
+package mcr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// service name of the SRV records published for Minecraft servers
+const SRVService = "minecraft"
+
+// resolves SRV records, replaced in tests
+var lookupSRV = net.DefaultResolver.LookupSRV
+
+// option to resolve the _minecraft._tcp SRV record of the client address when connecting, the target host
+// and port of the highest priority record replace the address and port. The configured address and port are
+// used when no record exists. Only use this when the record points at the RCon listener, SRV records usually
+// point at the game port
+func WithSRVLookup() Option {
+	return func(cn *Client) {
+		cn.srvLookup = true
+	}
+}
+
+// returns the address to dial, consulting the SRV record when enabled
+func (c *Client) dialAddress(ctx context.Context) string {
+	host, port := c.address, c.port
+	if c.srvLookup {
+		_, records, err := lookupSRV(ctx, SRVService, "tcp", c.address)
+		if err == nil && len(records) > 0 { //records are sorted by priority and randomized by weight
+			host, port = strings.TrimSuffix(records[0].Target, "."), int(records[0].Port)
+		}
+	}
+	return fmt.Sprintf("%s:%d", host, port)
+}
@@ -0,0 +1,61 @@
+package mcr
+
+import (
+	"context"
+)
+
+// health of the client connection
+type ConnState int
+
+const (
+	StateDisconnected ConnState = iota //Connect has not been called or the client was closed
+	StateConnected                     //the connection is ready for commands
+	StateStale                         //a failed request left data on the connection, it is drained before the next command
+	StateBroken                        //the connection broke during the last request, Reconnect is required
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnected:
+		return "connected"
+	case StateStale:
+		return "stale"
+	case StateBroken:
+		return "broken"
+	}
+	return "unknown"
+}
+
+// returns the health of the connection as of the last request, a dead connection is only detected once a
+// request fails
+func (c *Client) State() ConnState {
+	switch {
+	case c.connection == nil:
+		return StateDisconnected
+	case c.broken:
+		return StateBroken
+	case c.stale:
+		return StateStale
+	}
+	return StateConnected
+}
+
+// returns true if the client is connected and the last request did not break the connection
+func (c *Client) IsConnected() bool {
+	state := c.State()
+	return state == StateConnected || state == StateStale
+}
+
+// closes the connection, then redials and authenticates the client in place
+func (c *Client) Reconnect(password string) error {
+	return c.ReconnectContext(context.Background(), password)
+}
+
+// closes the connection, then redials and authenticates the client in place, the context is handled like
+// ConnectContext
+func (c *Client) ReconnectContext(ctx context.Context, password string) error {
+	c.disconnect()
+	return c.ConnectContext(ctx, password)
+}
@@ -0,0 +1,96 @@
+package mcr
+
+import (
+	"sync"
+	"time"
+)
+
+// number of one second buckets kept for the longest rolling window
+const statsBuckets = 15 * 60
+
+// command statistics over a rolling window
+type WindowStats struct {
+	Commands   int           //commands sent during the window
+	Errors     int           //commands that returned an error during the window
+	Rate       float64       //commands per second
+	ErrorRate  float64       //fraction of commands that returned an error
+	AvgLatency time.Duration //average round trip time of successful commands
+}
+
+// rolling command statistics over the last 1, 5, and 15 minutes
+type Stats struct {
+	OneMinute      WindowStats
+	FiveMinutes    WindowStats
+	FifteenMinutes WindowStats
+}
+
+// commands recorded during a single second
+type statsBucket struct {
+	second   int64
+	commands int
+	errors   int
+	latency  time.Duration
+}
+
+// per-second ring of command statistics, safe for concurrent use so Stats can be read while commands run
+type rollingStats struct {
+	mu      sync.Mutex
+	buckets [statsBuckets]statsBucket
+}
+
+// records a command that completed at the supplied time
+func (r *rollingStats) record(at time.Time, latency time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sec := at.Unix()
+	b := &r.buckets[sec%statsBuckets]
+	if b.second != sec { //bucket holds data from a previous lap of the ring
+		*b = statsBucket{second: sec}
+	}
+	b.commands++
+	if failed {
+		b.errors++
+		return
+	}
+	b.latency += latency
+}
+
+// summarizes the commands recorded during the window ending at the supplied time
+func (r *rollingStats) window(at time.Time, length time.Duration) WindowStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		ws      WindowStats
+		latency time.Duration
+	)
+	now := at.Unix()
+	seconds := int64(length / time.Second)
+	for _, b := range r.buckets {
+		if b.second > now-seconds && b.second <= now {
+			ws.Commands += b.commands
+			ws.Errors += b.errors
+			latency += b.latency
+		}
+	}
+
+	ws.Rate = float64(ws.Commands) / float64(seconds)
+	if ws.Commands > 0 {
+		ws.ErrorRate = float64(ws.Errors) / float64(ws.Commands)
+	}
+	if ok := ws.Commands - ws.Errors; ok > 0 {
+		ws.AvgLatency = latency / time.Duration(ok)
+	}
+	return ws
+}
+
+// returns rolling command statistics over the last 1, 5, and 15 minutes
+func (c *Client) Stats() Stats {
+	now := time.Now()
+	return Stats{
+		OneMinute:      c.stats.window(now, time.Minute),
+		FiveMinutes:    c.stats.window(now, time.Minute*5),
+		FifteenMinutes: c.stats.window(now, time.Minute*15),
+	}
+}
@@ -0,0 +1,342 @@
+// Package telnet implements a client for password protected telnet consoles such as the 7 Days to Die server
+// console. The client mirrors the Connect, Command, and Close methods of mcr.Client so tooling can switch
+// protocols by constructor
+package telnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+const (
+	//default values
+	DefaultPort        = 8081
+	DefaultTimeout     = time.Second * 10
+	DefaultQuietPeriod = time.Millisecond * 250
+
+	//telnet commands
+	iac  = 255
+	dont = 254
+	do   = 253
+	wont = 252
+	will = 251
+	sb   = 250
+	se   = 240
+)
+
+var (
+	//matches the password prompt, prompts are not followed by a newline
+	passwordPrompt = regexp.MustCompile(`(?i)password:\s*$`)
+	//matches replies to a rejected password
+	loginFailed = regexp.MustCompile(`(?i)incorrect|denied|invalid password|wrong password`)
+	//matches replies to an accepted password
+	loginSucceeded = regexp.MustCompile(`(?i)logon successful|login successful|welcome`)
+)
+
+// telnet console client, commands are sent one at a time
+type Client struct {
+	address string
+	port    int
+	timeout time.Duration
+	quiet   time.Duration
+	prompt  *regexp.Regexp
+
+	mu         sync.Mutex
+	connection net.Conn
+	pending    []byte //telnet command split across reads
+}
+
+// client option func skeleton
+type Option func(c *Client)
+
+// option to allow for custom port values
+func WithPort(port int) Option {
+	return func(c *Client) {
+		c.port = port
+	}
+}
+
+// option to allow for custom timeout values
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// option to allow for custom quiet periods, without a prompt a response ends once the console is silent for
+// the quiet period after sending output
+func WithQuietPeriod(quiet time.Duration) Option {
+	return func(c *Client) {
+		c.quiet = quiet
+	}
+}
+
+// option to end responses at a prompt printed by the console after each command, such as `^> $`. The prompt
+// is matched against the text after the last newline and removed from the response
+func WithPrompt(prompt *regexp.Regexp) Option {
+	return func(c *Client) {
+		c.prompt = prompt
+	}
+}
+
+// creates a new telnet client configured with the supplied options
+func NewClient(addr string, opts ...Option) *Client {
+	c := &Client{
+		address: addr,
+		port:    DefaultPort,
+		timeout: DefaultTimeout,
+		quiet:   DefaultQuietPeriod,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// connects to the console and enters the password at the password prompt
+func (c *Client) Connect(password string) error {
+	return c.ConnectContext(context.Background(), password)
+}
+
+// connects to the console, the context bounds the login along with the client timeout
+func (c *Client) ConnectContext(ctx context.Context, password string) error {
+	if password == "" {
+		return mcr.ErrMissingPassword
+	}
+	if strings.ContainsAny(password, "\r\n") {
+		return mcr.ErrInvalidPassword
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connection != nil {
+		return mcr.ErrAlreadyConnected
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(c.address, strconv.Itoa(c.port)))
+	if err != nil {
+		return err
+	}
+	c.connection = conn
+	c.pending = nil
+
+	err = c.login(ctx, password)
+	if err != nil {
+		conn.Close()
+		c.connection = nil
+		return err
+	}
+	return nil
+}
+
+func (c *Client) login(ctx context.Context, password string) error {
+	_, err := c.readUntil(ctx, func(text string) bool { return passwordPrompt.MatchString(text) }, false)
+	if err != nil {
+		return err
+	}
+	_, err = c.connection.Write([]byte(password + "\r\n"))
+	if err != nil {
+		return err
+	}
+
+	//the banner following the login is discarded
+	text, err := c.readUntil(ctx, func(text string) bool {
+		return loginFailed.MatchString(text) || passwordPrompt.MatchString(text) ||
+			(c.prompt != nil && c.prompt.MatchString(lastLine(text)))
+	}, true)
+	if err != nil {
+		return err
+	}
+	if loginFailed.MatchString(text) || (passwordPrompt.MatchString(text) && !loginSucceeded.MatchString(text)) {
+		return mcr.ErrAuthFailed
+	}
+	return nil
+}
+
+// sends a command to the console and returns the output
+func (c *Client) Command(cmd string) (string, error) {
+	return c.CommandContext(context.Background(), cmd)
+}
+
+// sends a command to the console and returns the lines printed until the prompt or the quiet period. Log
+// lines printed between commands are discarded, cancelling the context interrupts the pending write or read
+func (c *Client) CommandContext(ctx context.Context, cmd string) (string, error) {
+	if strings.ContainsAny(cmd, "\r\n") {
+		return "", fmt.Errorf("%w: telnet commands cannot contain line breaks", mcr.ErrInvalidArgument)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connection == nil {
+		return "", mcr.ErrNotConnected
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	c.discard()
+	_, err := c.connection.Write([]byte(cmd + "\r\n"))
+	if err != nil {
+		return "", c.fail(ctx, err)
+	}
+
+	var done func(string) bool
+	if c.prompt != nil {
+		done = func(text string) bool { return c.prompt.MatchString(lastLine(text)) }
+	}
+	text, err := c.readUntil(ctx, done, true)
+	if err != nil {
+		return "", c.fail(ctx, err)
+	}
+	if c.prompt != nil && c.prompt.MatchString(lastLine(text)) {
+		text = text[:len(text)-len(lastLine(text))]
+	}
+	return strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), nil
+}
+
+// closes the connection to the console
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connection == nil {
+		return nil
+	}
+	err := c.connection.Close()
+	c.connection = nil
+	return err
+}
+
+// reads console output until done reports true or, when quiet is set, the console is silent for the quiet
+// period after sending output. Telnet option negotiation is refused and removed from the text
+func (c *Client) readUntil(ctx context.Context, done func(text string) bool, quiet bool) (string, error) {
+	conn := c.connection //captured so the callback never touches a connection closed after the read
+	fired := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetReadDeadline(time.Unix(1, 0))
+		close(fired)
+	})
+	defer func() {
+		if !stop() { //the callback has started, wait so it cannot expire the deadline after it is cleared
+			<-fired
+		}
+		conn.SetReadDeadline(time.Time{})
+	}()
+
+	deadline, _ := ctx.Deadline()
+	var text []byte
+	buf := make([]byte, 4096)
+	for {
+		readDeadline := deadline
+		if quiet && len(text) > 0 && c.quiet > 0 {
+			if q := time.Now().Add(c.quiet); q.Before(deadline) {
+				readDeadline = q
+			}
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		c.connection.SetReadDeadline(readDeadline)
+
+		n, err := c.connection.Read(buf)
+		if n > 0 {
+			text = append(text, c.negotiate(buf[:n])...)
+			if done != nil && done(string(text)) {
+				return string(text), nil
+			}
+		}
+		if errors.Is(err, os.ErrDeadlineExceeded) && ctx.Err() == nil && time.Now().Before(deadline) {
+			return string(text), nil //quiet period passed
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// discards log lines the console printed since the last response so they are not mistaken for output of the
+// next command
+func (c *Client) discard() {
+	defer c.connection.SetReadDeadline(time.Time{})
+	buf := make([]byte, 4096)
+	for {
+		c.connection.SetReadDeadline(time.Now().Add(time.Millisecond))
+		n, err := c.connection.Read(buf)
+		if n > 0 {
+			c.negotiate(buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// removes telnet commands from the data, refusing every option the server offers or requests
+func (c *Client) negotiate(data []byte) []byte {
+	data = append(c.pending, data...)
+	c.pending = nil
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] != iac {
+			out = append(out, data[i])
+			continue
+		}
+		if i+1 >= len(data) {
+			c.pending = data[i:] //command split across reads
+			break
+		}
+		switch cmd := data[i+1]; cmd {
+		case iac:
+			out = append(out, iac)
+			i++
+		case do, dont, will, wont:
+			if i+2 >= len(data) {
+				c.pending = data[i:]
+				return out
+			}
+			reply := byte(wont)
+			if cmd == will || cmd == wont {
+				reply = dont
+			}
+			c.connection.Write([]byte{iac, reply, data[i+2]})
+			i += 2
+		case sb:
+			end := strings.Index(string(data[i:]), string([]byte{iac, se}))
+			if end < 0 {
+				c.pending = data[i:]
+				return out
+			}
+			i += end + 1
+		default:
+			i++
+		}
+	}
+	return out
+}
+
+// closes the connection after a failed command since the rest of the output cannot be told apart from the
+// next response, returning the context error when the context ended the command
+func (c *Client) fail(ctx context.Context, err error) error {
+	c.connection.Close()
+	c.connection = nil
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("%w: %w", mcr.ErrConnectionClosed, err)
+}
+
+// returns the text after the last newline
+func lastLine(text string) string {
+	return text[strings.LastIndexByte(text, '\n')+1:]
+}
@@ -0,0 +1,132 @@
+package telnet
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jake-young-dev/mcr"
+)
+
+// starts a console behaving like a 7 Days to Die server, the prompt is printed after every response when set.
+// The server offers to echo and requires the client to refuse before the password is accepted
+func startTestServer(t *testing.T, prompt string) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.Write([]byte{iac, will, 1})
+				conn.Write([]byte("Please enter password:"))
+				r := bufio.NewReader(conn)
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				refused := strings.HasPrefix(line, string([]byte{iac, dont, 1}))
+				if !refused || strings.TrimSpace(line[3:]) != "secret" {
+					conn.Write([]byte("Password incorrect, please enter password:"))
+					return
+				}
+				conn.Write([]byte("Logon successful.\r\n\r\n*** Connected with 7DTD server.\r\n" + prompt))
+
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					cmd := strings.TrimSpace(line)
+					conn.Write([]byte("*** Executing command '" + cmd + "' by Telnet from 127.0.0.1\r\n"))
+					if cmd == "version" {
+						time.Sleep(time.Millisecond * 20)
+						conn.Write([]byte("Game version: V 1.0\r\n"))
+					}
+					conn.Write([]byte(prompt))
+					if cmd == "log" {
+						conn.Write([]byte("INF Time: 1.00m FPS: 60.00\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// testing login, option negotiation, and responses ending after a quiet period
+func TestCommand(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t, "")), WithQuietPeriod(time.Millisecond*100))
+	err := c.Connect("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	res, err := c.Command("version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "*** Executing command 'version' by Telnet from 127.0.0.1\nGame version: V 1.0" {
+		t.Fatalf("unexpected response %q", res)
+	}
+
+	_, err = c.Command("log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 20)
+	res, err = c.Command("lp")
+	if err != nil || strings.Contains(res, "INF") {
+		t.Fatalf("log line was not discarded, got %q %v", res, err)
+	}
+}
+
+// testing responses ending at the prompt
+func TestPrompt(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t, "> ")), WithPrompt(regexp.MustCompile(`^> $`)),
+		WithQuietPeriod(time.Hour))
+	err := c.Connect("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	res, err := c.Command("version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(res, "Game version: V 1.0") {
+		t.Fatalf("unexpected response %q", res)
+	}
+}
+
+// testing rejected passwords
+func TestAuthFailed(t *testing.T) {
+	c := NewClient("127.0.0.1", WithPort(startTestServer(t, "")))
+	err := c.Connect("wrong")
+	if !errors.Is(err, mcr.ErrAuthFailed) {
+		t.Fatalf("expected auth failure, got %v", err)
+	}
+	_, err = c.CommandContext(context.Background(), "version")
+	if !errors.Is(err, mcr.ErrNotConnected) {
+		t.Fatalf("expected not connected, got %v", err)
+	}
+	_, err = c.CommandContext(context.Background(), "say hi\nshutdown")
+	if !errors.Is(err, mcr.ErrInvalidArgument) {
+		t.Fatalf("expected invalid argument, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package mcr
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// carries the RCon byte stream between the client and the server. Packet framing, quirks, reassembly, and
+// draining stay in the client so every transport behaves the same, a transport only provides an ordered and
+// reliable stream such as a tcp connection, websocket, or QUIC stream. Deadlines set on the returned
+// connection must interrupt pending reads and writes
+type Transport interface {
+	Dial(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// adapter allowing ordinary functions such as net.Dialer.DialContext to be used as a Transport
+type TransportFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+func (f TransportFunc) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	return f(ctx, network, address)
+}
+
+// default transport dialing tcp connections, Nagle's algorithm is configured by the client
+type TCPTransport struct {
+	Dialer net.Dialer
+}
+
+func (t *TCPTransport) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	return t.Dialer.DialContext(ctx, network, address)
+}
+
+// applies the socket options to a tcp connection
+func configureTCP(tcp *net.TCPConn, noDelay bool, keepAlive time.Duration) error {
+	err := tcp.SetNoDelay(noDelay)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case keepAlive < 0:
+		return tcp.SetKeepAlive(false)
+	case keepAlive > 0:
+		err = tcp.SetKeepAlive(true)
+		if err != nil {
+			return err
+		}
+		return tcp.SetKeepAlivePeriod(keepAlive)
+	}
+	return nil
+}
+
+// option to replace the tcp transport, for example with a websocket or QUIC stream
+func WithTransport(transport Transport) Option {
+	return func(cn *Client) {
+		cn.transport = transport
+	}
+}
@@ -0,0 +1,45 @@
+package mcr
+
+import (
+	"bytes"
+)
+
+// policy used to remove the bytes that follow the response body
+type TrimPolicy int
+
+const (
+	TrimPadding    TrimPolicy = iota //the two padding bytes are removed unless QuirkNoPadding is active
+	TrimNulls                        //every trailing null byte is removed, bodies of servers that skip the padding are left intact
+	TrimWhitespace                   //trailing null bytes and whitespace are removed
+	TrimNone                         //the body is returned as received including the padding
+)
+
+// option to choose how the bytes following the response body are removed. TrimPadding removes exactly two
+// bytes which cuts off the end of bodies from servers that do not send the padding, TrimNulls only removes
+// null bytes so it is safe for every server
+func WithTrimPolicy(policy TrimPolicy) Option {
+	return func(cn *Client) {
+		cn.trim = policy
+	}
+}
+
+// removes the bytes following the body according to the policy
+func trimBody(payload []byte, policy TrimPolicy, quirks Quirk) []byte {
+	switch policy {
+	case TrimNulls:
+		return bytes.TrimRight(payload, "\x00")
+	case TrimWhitespace:
+		return bytes.TrimRight(payload, "\x00 \t\r\n")
+	case TrimNone:
+		return payload
+	}
+
+	//bodies too short to hold the padding are empty
+	if quirks.Has(QuirkNoPadding) {
+		return payload
+	}
+	if len(payload) < PacketPaddingSize {
+		return payload[:0]
+	}
+	return payload[:len(payload)-PacketPaddingSize]
+}
@@ -0,0 +1,55 @@
+package mcr
+
+import (
+	"context"
+	"net"
+)
+
+// connection able to open tcp streams from a remote host, satisfied by *ssh.Client from
+// golang.org/x/crypto/ssh. Tunnels that also implement DialContext are dialed with the connect context
+type Tunnel interface {
+	Dial(network, address string) (net.Conn, error)
+}
+
+// option to dial the server through an ssh connection to the game host or a bastion, replacing a separately
+// managed port forward. The client address is resolved on the remote side so servers bound to localhost on the
+// game host are reachable. The module has no ssh dependency, establish the connection with
+// golang.org/x/crypto/ssh and pass the client:
+//
+//	sshClient, err := ssh.Dial("tcp", "bastion.example.com:22", sshConfig)
+//	client := mcr.NewClient("127.0.0.1", mcr.WithPort(25575), mcr.WithSSHTunnel(sshClient))
+//
+// The ssh connection is not closed by Close, it can be shared by several clients
+func WithSSHTunnel(tunnel Tunnel) Option {
+	return func(cn *Client) {
+		cn.transport = TransportFunc(func(ctx context.Context, network, address string) (net.Conn, error) {
+			if t, ok := tunnel.(interface {
+				DialContext(ctx context.Context, network, address string) (net.Conn, error)
+			}); ok {
+				return t.DialContext(ctx, network, address)
+			}
+
+			//plain Dial ignores the context, give up waiting once the context ends
+			type result struct {
+				conn net.Conn
+				err  error
+			}
+			done := make(chan result, 1)
+			go func() {
+				conn, err := tunnel.Dial(network, address)
+				done <- result{conn, err}
+			}()
+			select {
+			case res := <-done:
+				return res.conn, res.err
+			case <-ctx.Done():
+				go func() { //close the connection if the dial completes after all
+					if res := <-done; res.conn != nil {
+						res.conn.Close()
+					}
+				}()
+				return nil, ctx.Err()
+			}
+		})
+	}
+}
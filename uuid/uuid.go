@@ -0,0 +1,169 @@
+// Package uuid resolves Minecraft player names to UUIDs using the Mojang API with an offline-mode fallback
+package uuid
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//default values
+	DefaultBaseURL  = "https://api.mojang.com/users/profiles/minecraft/"
+	DefaultCacheTTL = time.Hour
+	DefaultTimeout  = time.Second * 10
+)
+
+// returned when the Mojang API has no profile for the player name
+var ErrNotFound = errors.New("no profile found for player name")
+
+// cached name lookup
+type entry struct {
+	uuid    string
+	expires time.Time
+}
+
+// player name to UUID resolver, lookups are cached and the resolver is safe for concurrent use
+type Resolver struct {
+	httpClient *http.Client
+	baseURL    string
+	ttl        time.Duration
+	fallback   bool //fall back to offline-mode UUIDs for names without a Mojang profile
+
+	mu      sync.Mutex
+	cache   map[string]entry //lowercase name to Mojang profile, profile names are case-insensitive
+	offline map[string]entry //exact name to offline-mode UUID, offline UUIDs depend on the name's case
+}
+
+// resolver option func skeleton
+type Option func(r *Resolver)
+
+// option to allow for a custom http client
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Resolver) {
+		r.httpClient = client
+	}
+}
+
+// option to allow for a custom profile lookup endpoint, the player name is appended to the url
+func WithBaseURL(base string) Option {
+	return func(r *Resolver) {
+		r.baseURL = base
+	}
+}
+
+// option to allow for custom cache durations
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(r *Resolver) {
+		r.ttl = ttl
+	}
+}
+
+// option to return offline-mode UUIDs for names without a Mojang profile, useful for offline-mode servers
+func WithOfflineFallback() Option {
+	return func(r *Resolver) {
+		r.fallback = true
+	}
+}
+
+// creates a new resolver configured with the supplied options
+func NewResolver(opts ...Option) *Resolver {
+	r := &Resolver{
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		baseURL:    DefaultBaseURL,
+		ttl:        DefaultCacheTTL,
+		cache:      make(map[string]entry),
+		offline:    make(map[string]entry),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// returns the dashed UUID for the player name. Names without a Mojang profile return ErrNotFound unless the
+// offline fallback is enabled
+func (r *Resolver) Resolve(name string) (string, error) {
+	return r.ResolveContext(context.Background(), name)
+}
+
+// returns the dashed UUID for the player name like Resolve, the context cancels the profile lookup
+func (r *Resolver) ResolveContext(ctx context.Context, name string) (string, error) {
+	key := strings.ToLower(name)
+	r.mu.Lock()
+	e, ok := r.cache[key]
+	if !ok || time.Now().After(e.expires) {
+		e, ok = r.offline[name]
+	}
+	r.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.uuid, nil
+	}
+
+	cache := r.cache
+	id, err := r.lookup(ctx, name)
+	if errors.Is(err, ErrNotFound) && r.fallback {
+		cache, key = r.offline, name
+		id, err = Offline(name), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	cache[key] = entry{uuid: id, expires: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+	return id, nil
+}
+
+// queries the Mojang API for the player profile
+func (r *Resolver) lookup(ctx context.Context, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+url.PathEscape(name), nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent, http.StatusNotFound:
+		return "", ErrNotFound
+	default:
+		return "", fmt.Errorf("unexpected profile lookup status: %s", res.Status)
+	}
+
+	var profile struct {
+		ID string `json:"id"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&profile)
+	if err != nil {
+		return "", err
+	}
+	if len(profile.ID) != 32 {
+		return "", fmt.Errorf("invalid profile id: %q", profile.ID)
+	}
+	id := profile.ID
+	return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:], nil
+}
+
+// returns the UUID an offline-mode server assigns to the player name, this matches the name based (version 3)
+// UUID the server derives from "OfflinePlayer:<name>"
+func Offline(name string) string {
+	h := md5.Sum([]byte("OfflinePlayer:" + name))
+	h[6] = h[6]&0x0f | 0x30 //version 3
+	h[8] = h[8]&0x3f | 0x80 //IETF variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[:4], h[4:6], h[6:8], h[8:10], h[10:])
+}
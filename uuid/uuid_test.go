@@ -0,0 +1,67 @@
+package uuid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testing offline-mode UUID generation against a known value
+func TestOffline(t *testing.T) {
+	if id := Offline("Notch"); id != "b50ad385-829d-3141-a216-7e7d7539ba7f" {
+		t.Fatalf("unexpected offline uuid %s", id)
+	}
+}
+
+// testing profile lookups, caching, and the offline fallback
+func TestResolve(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if strings.HasSuffix(r.URL.Path, "/Notch") {
+			w.Write([]byte(`{"id":"069a79f444e94726a5befca90e38aaf5","name":"Notch"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	r := NewResolver(WithBaseURL(srv.URL + "/"))
+	for i := 0; i < 2; i++ {
+		id, err := r.Resolve("Notch")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != "069a79f4-44e9-4726-a5be-fca90e38aaf5" {
+			t.Fatalf("unexpected uuid %s", id)
+		}
+	}
+	if calls != 1 {
+		t.Fatal("cached lookup was not used")
+	}
+
+	_, err := r.Resolve("nobody")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("expected missing profile to return ErrNotFound")
+	}
+
+	r = NewResolver(WithBaseURL(srv.URL+"/"), WithOfflineFallback())
+	id, err := r.Resolve("nobody")
+	if err != nil || id != Offline("nobody") {
+		t.Fatal("offline fallback was not used")
+	}
+	id, err = r.Resolve("Nobody") //offline UUIDs are case-sensitive so the cached entry does not apply
+	if err != nil || id != Offline("Nobody") || id == Offline("nobody") {
+		t.Fatal("offline fallback reused the uuid of a differently cased name")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = r.ResolveContext(ctx, "jeb_")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled lookup to fail, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package mcr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// polls the server every interval until it accepts authentication with the password, useful right after a
+// container starts while the server is still booting. The context bounds the total wait, the error from the
+// last attempt is included when the context ends first. Rejected passwords are returned immediately since
+// waiting longer cannot fix them
+func WaitForServer(ctx context.Context, addr string, port int, password string, interval time.Duration) error {
+	c := NewClient(addr, WithPort(port))
+	defer c.Close()
+	return c.waitConnect(ctx, password, interval)
+}
+
+// connects the client every interval until the server accepts the password, only dial and connection
+// errors are retried
+func (c *Client) waitConnect(ctx context.Context, password string, interval time.Duration) error {
+	for {
+		err := c.ConnectContext(ctx, password) //bounds auth too, servers can accept connections before they answer
+		if err == nil {
+			return nil
+		}
+		c.disconnect()
+		if errors.Is(err, ErrAuthFailed) || errors.Is(err, ErrMissingPassword) || errors.Is(err, ErrInvalidPassword) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ctx.Err(), err)
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Package webrcon implements the json over websocket RCon protocol used by Rust servers (rcon.web 1). The
+// client mirrors the Connect, Command, and Close methods of mcr.Client so tooling can switch protocols by
+// constructor
+package webrcon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jake-young-dev/mcr"
+	"github.com/jake-young-dev/mcr/internal/websocket"
+)
+
+const (
+	//default values
+	DefaultPort    = 28016
+	DefaultTimeout = time.Second * 10
+	DefaultName    = "WebRcon"
+
+	//identifiers at or below zero are used by the server for broadcast messages
+	maxIdentifier = 1<<31 - 1
+)
+
+// message sent by the server, either the response to a command or a broadcast such as chat and console output
+type Message struct {
+	Message    string `json:"Message"`
+	Identifier int    `json:"Identifier"`
+	Type       string `json:"Type"` //Generic, Log, Warning, Error, Chat, or Report
+	Stacktrace string `json:"Stacktrace,omitempty"`
+}
+
+// command sent to the server
+type request struct {
+	Identifier int    `json:"Identifier"`
+	Message    string `json:"Message"`
+	Name       string `json:"Name"`
+}
+
+// webrcon client, commands are sent one at a time
+type Client struct {
+	address   string
+	port      int
+	timeout   time.Duration
+	secure    bool
+	name      string
+	onMessage func(Message)
+
+	mu         sync.Mutex
+	connection *websocket.Conn
+	decoder    *json.Decoder
+	identifier int
+}
+
+// client option func skeleton
+type Option func(c *Client)
+
+// option to allow for custom port values
+func WithPort(port int) Option {
+	return func(c *Client) {
+		c.port = port
+	}
+}
+
+// option to allow for custom timeout values
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// option to connect with wss when the server sits behind a tls terminating proxy
+func WithTLS() Option {
+	return func(c *Client) {
+		c.secure = true
+	}
+}
+
+// option to set the name the server logs commands under
+func WithName(name string) Option {
+	return func(c *Client) {
+		c.name = name
+	}
+}
+
+// option to receive broadcast messages (chat, console output) that arrive while waiting for a command
+// response, the handler runs on the goroutine sending the command and must not call the client
+func WithMessageHandler(handler func(Message)) Option {
+	return func(c *Client) {
+		c.onMessage = handler
+	}
+}
+
+// creates a new webrcon client configured with the supplied options
+func NewClient(addr string, opts ...Option) *Client {
+	c := &Client{
+		address: addr,
+		port:    DefaultPort,
+		timeout: DefaultTimeout,
+		name:    DefaultName,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// connects to the server, the password is part of the websocket url and servers refuse the handshake when it
+// does not match
+func (c *Client) Connect(password string) error {
+	return c.ConnectContext(context.Background(), password)
+}
+
+// connects to the server, the context bounds the connection along with the client timeout
+func (c *Client) ConnectContext(ctx context.Context, password string) error {
+	if password == "" {
+		return mcr.ErrMissingPassword
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connection != nil {
+		return mcr.ErrAlreadyConnected
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	scheme := "ws"
+	if c.secure {
+		scheme = "wss"
+	}
+	u := url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(c.address, strconv.Itoa(c.port)),
+		Path:   "/" + password,
+	}
+	conn, err := websocket.Dial(ctx, u.String())
+	if errors.Is(err, websocket.ErrHandshake) {
+		return fmt.Errorf("%w: %w", mcr.ErrAuthFailed, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.connection = conn
+	c.decoder = json.NewDecoder(conn)
+	return nil
+}
+
+// sends a command to the server and returns the server response
+func (c *Client) Command(cmd string) (string, error) {
+	return c.CommandContext(context.Background(), cmd)
+}
+
+// sends a command to the server and returns the server response. Broadcast messages received while waiting
+// are passed to the message handler, cancelling the context interrupts the pending write or read
+func (c *Client) CommandContext(ctx context.Context, cmd string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connection == nil {
+		return "", mcr.ErrNotConnected
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	//the connection is captured so the cancellation callback never sees it replaced or cleared
+	conn := c.connection
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	done := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+		close(done)
+	})
+	defer func() {
+		if c.connection != conn { //closed by fail, which already stopped the callback
+			return
+		}
+		if !stop() { //the callback has started, wait so it cannot expire the deadline after it is cleared
+			<-done
+		}
+		conn.SetDeadline(time.Time{}) //the next command sets its own deadline
+	}()
+
+	c.identifier++
+	if c.identifier >= maxIdentifier {
+		c.identifier = 1
+	}
+	body, err := json.Marshal(request{Identifier: c.identifier, Message: cmd, Name: c.name})
+	if err != nil {
+		return "", err
+	}
+	err = conn.WriteText(body)
+	if err != nil {
+		return "", c.fail(ctx, stop, err)
+	}
+
+	for {
+		var msg Message
+		err = c.decoder.Decode(&msg)
+		if err != nil {
+			return "", c.fail(ctx, stop, err)
+		}
+		if msg.Identifier == c.identifier {
+			return msg.Message, nil
+		}
+		if c.onMessage != nil {
+			c.onMessage(msg)
+		}
+	}
+}
+
+// closes the connection after a failed command since a partially read message cannot be resynchronized,
+// returning the context error when the context ended the command. The cancellation callback is stopped before
+// the connection is torn down
+func (c *Client) fail(ctx context.Context, stop func() bool, err error) error {
+	stop()
+	c.connection.Close()
+	c.connection = nil
+	c.decoder = nil
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var ne net.Error
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) && errors.As(err, &ne) && ne.Timeout() {
+		return context.DeadlineExceeded
+	}
+	if errors.Is(err, net.ErrClosed) {
+		return mcr.ErrConnectionClosed
+	}
+	return fmt.Errorf("%w: %w", mcr.ErrConnectionClosed, err)
+}
+
+// closes the connection to the server
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connection == nil {
+		return nil
+	}
+	err := c.connection.Close()
+	c.connection = nil
+	c.decoder = nil
+	return err
+}
@@ -0,0 +1,119 @@
+package webrcon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jake-young-dev/mcr"
+	"github.com/jake-young-dev/mcr/internal/websocket"
+)
+
+// starts a server answering commands like a Rust server, a chat broadcast is sent before every response
+func startTestServer(t *testing.T) (string, int) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		dec := json.NewDecoder(conn)
+		for {
+			var req request
+			if dec.Decode(&req) != nil {
+				return
+			}
+			if req.Message == "hang" {
+				continue
+			}
+			chat, _ := json.Marshal(Message{Message: "[CHAT] player: hi", Identifier: 0, Type: "Chat"})
+			res, _ := json.Marshal(Message{Message: "ran " + req.Message, Identifier: req.Identifier, Type: "Generic"})
+			conn.WriteText(chat)
+			conn.WriteText(res)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	u, _ := url.Parse(srv.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	p, _ := strconv.Atoi(port)
+	return host, p
+}
+
+// testing commands, broadcast messages, and reconnecting after close
+func TestCommand(t *testing.T) {
+	host, port := startTestServer(t)
+	var chats []Message
+	c := NewClient(host, WithPort(port), WithMessageHandler(func(m Message) { chats = append(chats, m) }))
+
+	_, err := c.Command("status")
+	if !errors.Is(err, mcr.ErrNotConnected) {
+		t.Fatalf("expected not connected, got %v", err)
+	}
+	err = c.Connect("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for _, cmd := range []string{"status", "players"} {
+		res, err := c.Command(cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != "ran "+cmd {
+			t.Fatalf("unexpected response %q", res)
+		}
+	}
+	if len(chats) != 2 || chats[0].Type != "Chat" {
+		t.Fatalf("unexpected broadcast messages %+v", chats)
+	}
+}
+
+// testing rejected passwords are reported as authentication failures
+func TestAuthFailed(t *testing.T) {
+	host, port := startTestServer(t)
+	c := NewClient(host, WithPort(port))
+	err := c.Connect("wrong")
+	if !errors.Is(err, mcr.ErrAuthFailed) {
+		t.Fatalf("expected auth failure, got %v", err)
+	}
+	if !errors.Is(c.Connect(""), mcr.ErrMissingPassword) {
+		t.Fatal("expected missing password")
+	}
+}
+
+// testing cancelled commands close the connection
+func TestCommandContext(t *testing.T) {
+	host, port := startTestServer(t)
+	c := NewClient(host, WithPort(port))
+	err := c.Connect("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	_, err = c.CommandContext(ctx, "hang")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	_, err = c.Command("status")
+	if !errors.Is(err, mcr.ErrNotConnected) {
+		t.Fatalf("expected the connection to be closed, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !(js && wasm)
+
+package mcr
+
+import (
+	"context"
+	"net"
+
+	"github.com/jake-young-dev/mcr/internal/websocket"
+)
+
+// option to connect through a websocket to tcp relay such as cmd/mcr-wstunnel, the relay forwards binary
+// websocket messages to the RCon port unchanged. The url uses the ws or wss scheme, the client address and port
+// are ignored since the relay decides which server to connect to
+func WithWebSocketProxy(url string) Option {
+	return func(cn *Client) {
+		cn.transport = TransportFunc(func(ctx context.Context, network, address string) (net.Conn, error) {
+			return websocket.Dial(ctx, url)
+		})
+	}
+}
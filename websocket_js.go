@@ -0,0 +1,201 @@
+//go:build js && wasm
+
+package mcr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+// option to connect through a websocket to tcp relay when running in the browser, the relay forwards binary
+// websocket messages to the RCon port unchanged. The client address and port are ignored, the relay decides
+// which server to connect to
+func WithWebSocketProxy(url string) Option {
+	return func(cn *Client) {
+		cn.transport = TransportFunc(func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialWebSocket(ctx, url)
+		})
+	}
+}
+
+// address of a websocket relay
+type wsAddr string
+
+func (a wsAddr) Network() string { return "websocket" }
+func (a wsAddr) String() string  { return string(a) }
+
+// net.Conn backed by the browser WebSocket api
+type wsConn struct {
+	ws     js.Value
+	url    string
+	funcs  []js.Func
+	notify chan struct{} //signalled when data arrives or the socket closes
+
+	mu           sync.Mutex
+	buf          []byte
+	err          error //set once the socket closes
+	readDeadline time.Time
+}
+
+// opens a websocket to the relay and waits for it to connect
+func dialWebSocket(ctx context.Context, url string) (net.Conn, error) {
+	c := &wsConn{
+		ws:     js.Global().Get("WebSocket").New(url),
+		url:    url,
+		notify: make(chan struct{}, 1),
+	}
+	c.ws.Set("binaryType", "arraybuffer")
+
+	opened := make(chan error, 1)
+	//callbacks must never block, they run on the javascript event loop
+	c.on("open", func(ev js.Value) {
+		select {
+		case opened <- nil:
+		default:
+		}
+	})
+	c.on("error", func(ev js.Value) {
+		select {
+		case opened <- errors.New("websocket connection failed"):
+		default:
+		}
+	})
+	c.on("message", func(ev js.Value) {
+		data := js.Global().Get("Uint8Array").New(ev.Get("data"))
+		b := make([]byte, data.Length())
+		js.CopyBytesToGo(b, data)
+		c.mu.Lock()
+		c.buf = append(c.buf, b...)
+		c.mu.Unlock()
+		c.signal()
+	})
+	c.on("close", func(ev js.Value) {
+		c.mu.Lock()
+		if c.err == nil {
+			c.err = net.ErrClosed
+		}
+		c.mu.Unlock()
+		c.signal()
+	})
+
+	select {
+	case err := <-opened:
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	case <-ctx.Done():
+		c.Close()
+		return nil, ctx.Err()
+	}
+	return c, nil
+}
+
+// registers a websocket event handler
+func (c *wsConn) on(event string, fn func(ev js.Value)) {
+	f := js.FuncOf(func(this js.Value, args []js.Value) any {
+		fn(args[0])
+		return nil
+	})
+	c.funcs = append(c.funcs, f)
+	c.ws.Call("addEventListener", event, f)
+}
+
+// wakes a pending read without blocking
+func (c *wsConn) signal() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (c *wsConn) Read(b []byte) (int, error) {
+	for {
+		c.mu.Lock()
+		if len(c.buf) > 0 {
+			n := copy(b, c.buf)
+			c.buf = c.buf[n:]
+			c.mu.Unlock()
+			return n, nil
+		}
+		err, deadline := c.err, c.readDeadline
+		c.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+
+		var (
+			expired <-chan time.Time
+			timer   *time.Timer
+		)
+		if !deadline.IsZero() {
+			wait := time.Until(deadline)
+			if wait <= 0 {
+				return 0, os.ErrDeadlineExceeded
+			}
+			timer = time.NewTimer(wait)
+			expired = timer.C
+		}
+
+		select {
+		case <-c.notify:
+		case <-expired:
+			return 0, os.ErrDeadlineExceeded
+		}
+		if timer != nil { //stopped each iteration, deferring would keep every timer alive until Read returns
+			timer.Stop()
+		}
+	}
+}
+
+func (c *wsConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	err := c.err
+	c.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	arr := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(arr, b)
+	c.ws.Call("send", arr)
+	return len(b), nil
+}
+
+func (c *wsConn) Close() error {
+	c.ws.Call("close")
+	for _, f := range c.funcs {
+		f.Release()
+	}
+	c.funcs = nil
+	c.mu.Lock()
+	c.err = net.ErrClosed
+	c.mu.Unlock()
+	c.signal()
+	return nil
+}
+
+func (c *wsConn) LocalAddr() net.Addr  { return wsAddr("browser") }
+func (c *wsConn) RemoteAddr() net.Addr { return wsAddr(c.url) }
+
+func (c *wsConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *wsConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	c.signal()
+	return nil
+}
+
+// writes are handed to the browser without blocking so write deadlines have no effect
+func (c *wsConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package wstunnel relays websocket connections to an RCon server over tcp, letting clients that can only
+// speak websockets (browsers, restrictive networks) reach the server. Payloads are forwarded unchanged so the
+// RCon password is still required, serve the handler over tls when the relay is exposed publicly
+package wstunnel
+
+import (
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jake-young-dev/mcr/internal/websocket"
+)
+
+// default time allowed to connect to the RCon server
+const DefaultDialTimeout = 10 * time.Second
+
+// http handler relaying each websocket connection to the RCon server
+type Handler struct {
+	Target      string        //address of the RCon server
+	DialTimeout time.Duration //time allowed to connect to the RCon server, defaults to DefaultDialTimeout
+	Logger      *log.Logger   //optional logger for connection events
+
+	//reports whether a request's Origin is allowed, defaults to SameOrigin. Browsers send cookies and
+	//credentials with cross-site websocket requests so pages on other hosts must not reach the relay
+	CheckOrigin func(r *http.Request) bool
+}
+
+// allows requests without an Origin header and requests whose Origin host matches the request host
+func SameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// upgrades the request and relays bytes in both directions until either side closes
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	check := h.CheckOrigin
+	if check == nil {
+		check = SameOrigin
+	}
+	if !check(r) {
+		h.logf("%s: origin %q rejected", r.RemoteAddr, r.Header.Get("Origin"))
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	//upgrading first keeps plain http requests from opening connections to the RCon server
+	ws, err := websocket.Upgrade(w, r)
+	if err != nil {
+		h.logf("%s: %v", r.RemoteAddr, err)
+		return
+	}
+	defer ws.Close()
+
+	timeout := h.DialTimeout
+	if timeout <= 0 {
+		timeout = DefaultDialTimeout
+	}
+	upstream, err := net.DialTimeout("tcp", h.Target, timeout)
+	if err != nil {
+		h.logf("%s: dial %s: %v", r.RemoteAddr, h.Target, err)
+		return
+	}
+	h.logf("%s: relaying to %s", r.RemoteAddr, h.Target)
+
+	//the first copy to finish ends the relay, closing the tcp side and expiring the websocket read stops the
+	//other copy so the deferred ws.Close sends the only close frame
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream, ws)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(ws, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+	upstream.Close()
+	ws.SetReadDeadline(time.Now())
+	<-done
+	h.logf("%s: closed", r.RemoteAddr)
+}
+
+func (h *Handler) logf(format string, args ...any) {
+	if h.Logger != nil {
+		h.Logger.Printf(format, args...)
+	}
+}
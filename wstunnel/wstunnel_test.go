@@ -0,0 +1,145 @@
+package wstunnel
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jake-young-dev/mcr"
+	"github.com/jake-young-dev/mcr/sim"
+)
+
+// testing a client connects and runs commands through the relay
+func TestRelay(t *testing.T) {
+	s := &sim.Server{Password: "secret", Players: []string{"Steve"}}
+	err := s.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	srv := httptest.NewServer(&Handler{Target: s.Addr().String()})
+	defer srv.Close()
+
+	client := mcr.NewClient("unused", mcr.WithWebSocketProxy("ws"+strings.TrimPrefix(srv.URL, "http")))
+	err = client.Connect("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	res, err := client.Command("list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(res, "Steve") {
+		t.Fatalf("unexpected response %q", res)
+	}
+
+	//large responses arrive split across websocket messages
+	res, err = client.Command("help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len("/help [<command>]\n")*512 {
+		t.Fatalf("unexpected help response length %d", len(res))
+	}
+}
+
+// testing a server disconnect closes the websocket with a single close frame
+func TestUpstreamClosed(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	srv := httptest.NewServer(&Handler{Target: ln.Addr().String()})
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	err = req.Write(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil || res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("unexpected upgrade response %v %v", res, err)
+	}
+
+	frames, err := io.ReadAll(br)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(frames, []byte{0x88, 0x00}) {
+		t.Fatalf("expected a single close frame, got % x", frames)
+	}
+}
+
+// testing the relay reports an unreachable server
+func TestUnavailable(t *testing.T) {
+	srv := httptest.NewServer(&Handler{Target: "127.0.0.1:1"})
+	defer srv.Close()
+
+	client := mcr.NewClient("unused", mcr.WithWebSocketProxy("ws"+strings.TrimPrefix(srv.URL, "http")))
+	err := client.Connect("secret")
+	if err == nil {
+		client.Close()
+		t.Fatal("expected connect to fail")
+	}
+}
+
+// testing cross-origin handshakes are rejected before reaching the server
+func TestOrigin(t *testing.T) {
+	srv := httptest.NewServer(&Handler{Target: "127.0.0.1:1"})
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Origin", "https://attacker.example")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", res.StatusCode)
+	}
+
+	u, _ := url.Parse(srv.URL)
+	r := httptest.NewRequest(http.MethodGet, srv.URL, nil)
+	r.Header.Set("Origin", "http://"+u.Host)
+	if !SameOrigin(r) {
+		t.Fatal("expected same origin to be allowed")
+	}
+}